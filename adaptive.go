@@ -0,0 +1,255 @@
+package sqsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxConcurrencyChanges is the number of recent concurrency changes which are kept for monitor.
+const maxConcurrencyChanges = 20
+
+// AdaptiveConcurrency adjusts concurrency of consumer by latency and errors of invocations,
+// in the same way as AIMD congestion control of TCP.
+// At every Interval, concurrency is
+//   - multiplied by Backoff, if the ratio of overloaded invocations, which are 5xx responses and timeouts, exceeds ErrorRate
+//   - multiplied by Backoff, if average latency exceeds Tolerance times baseline latency
+//   - increased by 1, if all slots were used in the interval
+//
+// and is kept between Min and Max.
+// Baseline latency is the lowest average latency, and it slowly follows current latency so that permanent change of latency is accepted.
+type AdaptiveConcurrency struct {
+	Min       int
+	Max       int
+	Interval  time.Duration
+	ErrorRate float64
+	Tolerance float64
+	Backoff   float64
+}
+
+// ConsumerAdaptiveConcurrency enables adaptive concurrency of consumer.
+// Zero fields are filled by defaults: Min is 1, Max is parallel count of consumer, Interval is 5s,
+// ErrorRate is 0.1, Tolerance is 2 and Backoff is 0.75.
+func ConsumerAdaptiveConcurrency(c AdaptiveConcurrency) ConsumerParameter {
+	return func(p *consumerParams) {
+		p.adaptive = &c
+	}
+}
+
+// withDefaults fills zero fields of c.
+func (c AdaptiveConcurrency) withDefaults(capacity int) AdaptiveConcurrency {
+	if c.Min < 1 {
+		c.Min = 1
+	}
+	if c.Max < 1 {
+		c.Max = capacity
+	}
+	if c.Max < c.Min {
+		c.Max = c.Min
+	}
+	if c.Interval <= 0 {
+		c.Interval = 5 * time.Second
+	}
+	if c.ErrorRate <= 0 {
+		c.ErrorRate = 0.1
+	}
+	if c.Tolerance <= 1 {
+		c.Tolerance = 2
+	}
+	if c.Backoff <= 0 || c.Backoff >= 1 {
+		c.Backoff = 0.75
+	}
+	return c
+}
+
+// clamp keeps n between Min and Max.
+func (c AdaptiveConcurrency) clamp(n int) int {
+	if n < c.Min {
+		return c.Min
+	}
+	if n > c.Max {
+		return c.Max
+	}
+	return n
+}
+
+// isOverloaded returns true if err shows that invoker is overloaded, such as 5xx response and timeout.
+func isOverloaded(err error) bool {
+	if err == nil {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var timeoutErr interface{ Timeout() bool }
+	if errors.As(err, &timeoutErr) && timeoutErr.Timeout() {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.DeadlineExceeded, codes.Unavailable, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// adaptiveLimiter observes invocations and decides concurrency of worker.
+type adaptiveLimiter struct {
+	conf AdaptiveConcurrency
+
+	mu sync.Mutex
+	// inflight and peak are the number of running invocations, and the maximum of it in the interval.
+	inflight int
+	peak     int
+	// samples, overloads and total are results of invocations in the interval.
+	samples   int
+	overloads int
+	total     time.Duration
+
+	latency   time.Duration
+	baseline  time.Duration
+	errorRate float64
+	changes   []*ConcurrencyChange
+}
+
+func newAdaptiveLimiter(conf AdaptiveConcurrency) *adaptiveLimiter {
+	return &adaptiveLimiter{conf: conf}
+}
+
+// begin records start of invocation.
+func (l *adaptiveLimiter) begin() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight++
+	if l.inflight > l.peak {
+		l.peak = l.inflight
+	}
+}
+
+// end records result of invocation.
+// Latency of overloaded invocation is not recorded, because timeout hides actual latency.
+func (l *adaptiveLimiter) end(d time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	l.samples++
+	if isOverloaded(err) {
+		l.overloads++
+		return
+	}
+	l.total += d
+}
+
+// next decides concurrency from results in the interval, and starts next interval.
+// it returns the reason if concurrency should be changed.
+func (l *adaptiveLimiter) next(current int) (int, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	samples, overloads, total, peak := l.samples, l.overloads, l.total, l.peak
+	l.samples, l.overloads, l.total, l.peak = 0, 0, 0, l.inflight
+
+	if samples == 0 {
+		return current, ""
+	}
+	l.errorRate = float64(overloads) / float64(samples)
+	if succeeded := samples - overloads; succeeded > 0 {
+		l.latency = total / time.Duration(succeeded)
+		switch {
+		case l.baseline == 0 || l.latency < l.baseline:
+			l.baseline = l.latency
+		default:
+			l.baseline += (l.latency - l.baseline) / 20
+		}
+	}
+
+	switch {
+	case l.errorRate > l.conf.ErrorRate:
+		return l.decrease(current), fmt.Sprintf("error rate %.2f exceeds %.2f", l.errorRate, l.conf.ErrorRate)
+	case float64(l.latency) > float64(l.baseline)*l.conf.Tolerance:
+		return l.decrease(current), fmt.Sprintf("latency %s exceeds %.1f times baseline %s", l.latency, l.conf.Tolerance, l.baseline)
+	case peak >= current && current < l.conf.Max:
+		return l.conf.clamp(current + 1), "all slots are used"
+	}
+	return current, ""
+}
+
+func (l *adaptiveLimiter) decrease(current int) int {
+	if current <= l.conf.Min {
+		return current
+	}
+	n := int(float64(current) * l.conf.Backoff)
+	if n >= current {
+		n = current - 1
+	}
+	return l.conf.clamp(n)
+}
+
+// record keeps change of concurrency for monitor.
+func (l *adaptiveLimiter) record(prev, n int, reason string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes = append(l.changes, &ConcurrencyChange{
+		Previous:    int32(prev),
+		Concurrency: int32(n),
+		Reason:      reason,
+		ChangedAt:   timestamppb.Now(),
+	})
+	if len(l.changes) > maxConcurrencyChanges {
+		l.changes = l.changes[len(l.changes)-maxConcurrencyChanges:]
+	}
+}
+
+func (l *adaptiveLimiter) status() *AdaptiveConcurrencyStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return &AdaptiveConcurrencyStatus{
+		Min:             int32(l.conf.Min),
+		Max:             int32(l.conf.Max),
+		Latency:         durationpb.New(l.latency),
+		BaselineLatency: durationpb.New(l.baseline),
+		ErrorRate:       l.errorRate,
+		Changes:         append([]*ConcurrencyChange(nil), l.changes...),
+	}
+}
+
+// runLimiter adjusts concurrency of worker at every interval until ctx is done.
+func (w *worker) runLimiter(ctx context.Context) {
+	tick := time.NewTicker(w.limiter.conf.Interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			w.adjustConcurrency()
+		}
+	}
+}
+
+// adjustConcurrency changes concurrency by the decision of limiter.
+// Unlike SetConcurrency, it does not wait for running tasks when it shrinks.
+func (w *worker) adjustConcurrency() {
+	var n int
+	var reason string
+	prev := w.resize(func(current int) (int, string) {
+		n, reason = w.limiter.next(current)
+		if n == current {
+			return current, ""
+		}
+		return n, reason
+	})
+	if n == prev {
+		return
+	}
+	getLogger().Info("concurrency is adjusted.", "concurrency", n, "previous", prev, "reason", reason)
+}
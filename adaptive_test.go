@@ -0,0 +1,171 @@
+package sqsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsOverloaded(t *testing.T) {
+	for _, tt := range []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{errors.New("broken"), false},
+		{&StatusError{StatusCode: 400}, false},
+		{&StatusError{StatusCode: 503}, true},
+		{fmt.Errorf("failed: %w", &StatusError{StatusCode: 500}), true},
+		{context.DeadlineExceeded, true},
+		{os.ErrDeadlineExceeded, true},
+		{status.Error(codes.Unavailable, "unavailable"), true},
+		{status.Error(codes.InvalidArgument, "invalid"), false},
+		{&ExitCodeError{ExitCode: 1}, false},
+	} {
+		assert.Equal(t, tt.expected, isOverloaded(tt.err), tt.err)
+	}
+}
+
+func TestAdaptiveLimiter(t *testing.T) {
+	l := newAdaptiveLimiter(AdaptiveConcurrency{Max: 4}.withDefaults(2))
+	assert.Equal(t, AdaptiveConcurrency{Min: 1, Max: 4, Interval: 5 * time.Second, ErrorRate: 0.1, Tolerance: 2, Backoff: 0.75}, l.conf)
+
+	observe := func(n int, d time.Duration, err error) {
+		for i := 0; i < n; i++ {
+			l.begin()
+		}
+		for i := 0; i < n; i++ {
+			l.end(d, err)
+		}
+	}
+
+	n, reason := l.next(2)
+	assert.Equal(t, 2, n, "no invocation")
+	assert.Empty(t, reason)
+
+	observe(1, 10*time.Millisecond, nil)
+	n, _ = l.next(2)
+	assert.Equal(t, 2, n, "slots are not used up")
+
+	observe(2, 10*time.Millisecond, nil)
+	n, reason = l.next(2)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "all slots are used", reason)
+
+	observe(4, 10*time.Millisecond, nil)
+	n, _ = l.next(4)
+	assert.Equal(t, 4, n, "limited by max")
+
+	observe(4, 30*time.Millisecond, nil)
+	n, reason = l.next(4)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "latency 30ms exceeds 2.0 times baseline 11ms", reason)
+
+	for i := 0; i < 9; i++ {
+		observe(1, 10*time.Millisecond, nil)
+	}
+	observe(1, time.Second, context.DeadlineExceeded)
+	n, reason = l.next(3)
+	assert.Equal(t, 3, n, "error rate does not exceed threshold")
+	assert.Empty(t, reason)
+
+	observe(2, time.Second, &StatusError{StatusCode: 502})
+	n, reason = l.next(3)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "error rate 1.00 exceeds 0.10", reason)
+
+	observe(1, time.Second, &StatusError{StatusCode: 502})
+	n, _ = l.next(1)
+	assert.Equal(t, 1, n, "limited by min")
+
+	for i := 0; i < maxConcurrencyChanges+5; i++ {
+		l.record(i, i+1, "test")
+	}
+	st := l.status()
+	assert.Equal(t, int32(1), st.GetMin())
+	assert.Equal(t, int32(4), st.GetMax())
+	assert.Equal(t, 1.0, st.GetErrorRate())
+	if assert.Len(t, st.GetChanges(), maxConcurrencyChanges) {
+		assert.Equal(t, int32(maxConcurrencyChanges+5), st.GetChanges()[maxConcurrencyChanges-1].GetConcurrency())
+	}
+}
+
+func TestWorkerAdaptiveConcurrency(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		return &StatusError{StatusCode: 503}
+	}
+
+	broker := make(chan Message, 4)
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, &testQueueOperator{},
+		ConsumerAdaptiveConcurrency(AdaptiveConcurrency{Min: 1, Max: 3, Interval: 20 * time.Millisecond}))
+	monitor := NewMonitoringService(w)
+
+	st, err := monitor.Status(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), st.GetConcurrency(), "limited by max")
+
+	for i := 0; i < 10; i++ {
+		broker <- Message{ID: fmt.Sprintf("id:%d", i)}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	st, err = monitor.Status(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), st.GetConcurrency())
+	adaptive := st.GetAdaptive()
+	require.NotNil(t, adaptive)
+	assert.Equal(t, int32(1), adaptive.GetMin())
+	assert.Equal(t, int32(3), adaptive.GetMax())
+	require.NotEmpty(t, adaptive.GetChanges())
+	first := adaptive.GetChanges()[0]
+	assert.Equal(t, int32(3), first.GetPrevious())
+	assert.Equal(t, int32(2), first.GetConcurrency())
+	assert.Equal(t, "error rate 1.00 exceeds 0.10", first.GetReason())
+
+	_, err = monitor.SetConcurrency(ctx, &SetConcurrencyRequest{Concurrency: 2})
+	require.NoError(t, err)
+	st, err = monitor.Status(ctx, nil)
+	require.NoError(t, err)
+	changes := st.GetAdaptive().GetChanges()
+	assert.Equal(t, "changed by SetConcurrency", changes[len(changes)-1].GetReason())
+}
+
+func TestWorkerConcurrencyChangesAreSerialized(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		return nil
+	}
+
+	w := startWorker(ctx, testInvoker(testInvokerFn), make(chan Message), &testQueueOperator{},
+		ConsumerAdaptiveConcurrency(AdaptiveConcurrency{Min: 1, Max: 8, Interval: time.Hour}))
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 10*maxConcurrencyChanges; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			_, err := w.SetConcurrency(ctx, n%8+1)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	changes := w.limiter.status().GetChanges()
+	require.Len(t, changes, maxConcurrencyChanges)
+	for i := 1; i < len(changes); i++ {
+		assert.Equal(t, changes[i-1].GetConcurrency(), changes[i].GetPrevious(), "change %d", i)
+	}
+	assert.Equal(t, int32(w.slots.capacity()), changes[len(changes)-1].GetConcurrency())
+}
@@ -0,0 +1,535 @@
+package memorybackend
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sqsd "github.com/taiyoh/sqsd/v2"
+)
+
+// Scheme is URL scheme of in-memory queue.
+const Scheme = "memory"
+
+// URL returns queue URL of in-memory queue.
+func URL(name string) string {
+	return Scheme + "://" + name
+}
+
+// QueueName returns name of queue from queue URL.
+// Both "memory://name" and SQS style URL such as "http://localhost:9324/000000000000/name" are accepted.
+func QueueName(queueURL string) (string, error) {
+	u, err := url.Parse(queueURL)
+	if err != nil {
+		return "", err
+	}
+	name := u.Host
+	if p := strings.Trim(u.Path, "/"); p != "" {
+		name = path.Base(p)
+	}
+	if u.Scheme == Scheme && u.Path != "" {
+		return "", fmt.Errorf("invalid queue URL: %s", queueURL)
+	}
+	if name == "" {
+		return "", fmt.Errorf("queue name is empty: %s", queueURL)
+	}
+	return name, nil
+}
+
+// Config is the setting of queue.
+type Config struct {
+	// VisibilityTimeout is used when receiving request does not specify it. default is 30 seconds.
+	VisibilityTimeout time.Duration
+	// Delay is the default delay of sent messages.
+	Delay time.Duration
+	// DeadLetterQueue is the name of queue which messages are moved to,
+	// when they are received more than MaxReceiveCount.
+	DeadLetterQueue string
+	MaxReceiveCount int
+}
+
+// parseConfig reads Config from query of queue URL.
+//
+//	memory://jobs?visibility_timeout=30s&delay=0s&dead_letter_queue=jobs-dlq&max_receive_count=3
+func parseConfig(queueURL string) (Config, error) {
+	conf := Config{VisibilityTimeout: 30 * time.Second}
+	u, err := url.Parse(queueURL)
+	if err != nil {
+		return conf, err
+	}
+	q := u.Query()
+	for key, parse := range map[string]func(string) error{
+		"visibility_timeout": func(v string) (err error) {
+			conf.VisibilityTimeout, err = time.ParseDuration(v)
+			return
+		},
+		"delay": func(v string) (err error) {
+			conf.Delay, err = time.ParseDuration(v)
+			return
+		},
+		"dead_letter_queue": func(v string) error {
+			conf.DeadLetterQueue = v
+			return nil
+		},
+		"max_receive_count": func(v string) (err error) {
+			conf.MaxReceiveCount, err = strconv.Atoi(v)
+			return
+		},
+	} {
+		if v := q.Get(key); v != "" {
+			if err := parse(v); err != nil {
+				return conf, fmt.Errorf("invalid %s: %w", key, err)
+			}
+		}
+	}
+	return conf, nil
+}
+
+// dedupInterval is the interval of deduplication in FIFO queue.
+const dedupInterval = 5 * time.Minute
+
+type message struct {
+	id              string
+	body            string
+	attributes      map[string]sqsd.MessageAttribute
+	groupID         string
+	deduplicationID string
+	sentAt          time.Time
+	visibleAt       time.Time
+	firstReceivedAt time.Time
+	receiveCount    int
+	receipt         string
+	sequence        int64
+}
+
+type queue struct {
+	name     string
+	conf     Config
+	fifo     bool
+	messages []*message
+	dedup    map[string]dedupEntry
+	sequence int64
+}
+
+type dedupEntry struct {
+	id     string
+	sentAt time.Time
+}
+
+// QueueStats is the number of messages in queue.
+type QueueStats struct {
+	// Visible is the number of messages which can be received.
+	Visible int `json:"visible"`
+	// NotVisible is the number of messages which are received but not deleted yet.
+	NotVisible int `json:"not_visible"`
+	// Delayed is the number of messages which are sent with delay and not visible yet.
+	Delayed int `json:"delayed"`
+}
+
+// Backend is in-memory sqsd.QueueBackend.
+// It supports visibility timeout, receive count, delay, dead-letter queue redrive,
+// and message group ordering of FIFO queue which has ".fifo" suffix.
+type Backend struct {
+	mu     sync.Mutex
+	queues map[string]*queue
+	// changed is closed and renewed when messages may become receivable.
+	changed chan struct{}
+	now     func() time.Time
+}
+
+var _ sqsd.QueueBackend = (*Backend)(nil)
+
+// New returns Backend object.
+func New() *Backend {
+	return &Backend{
+		queues:  make(map[string]*queue),
+		changed: make(chan struct{}),
+		now:     time.Now,
+	}
+}
+
+// ErrQueueExists shows that queue which has the same name is already created.
+var ErrQueueExists = errors.New("queue already exists")
+
+// CreateQueue creates queue with config.
+// Queues are also created by default config when they are used first.
+func (b *Backend) CreateQueue(name string, conf Config) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.queues[name]; ok {
+		return ErrQueueExists
+	}
+	if conf.VisibilityTimeout <= 0 {
+		conf.VisibilityTimeout = 30 * time.Second
+	}
+	b.queues[name] = newQueue(name, conf)
+	return nil
+}
+
+// ErrQueueNotFound shows that queue is not created.
+var ErrQueueNotFound = errors.New("queue does not exist")
+
+// QueueConfig returns config of queue.
+func (b *Backend) QueueConfig(name string) (Config, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	q, ok := b.queues[name]
+	if !ok {
+		return Config{}, ErrQueueNotFound
+	}
+	return q.conf, nil
+}
+
+// DeleteQueue deletes queue and its messages.
+func (b *Backend) DeleteQueue(name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.queues[name]; !ok {
+		return ErrQueueNotFound
+	}
+	delete(b.queues, name)
+	b.notifyLocked()
+	return nil
+}
+
+func newQueue(name string, conf Config) *queue {
+	return &queue{
+		name:  name,
+		conf:  conf,
+		fifo:  strings.HasSuffix(name, ".fifo"),
+		dedup: make(map[string]dedupEntry),
+	}
+}
+
+// queueLocked returns queue by URL, and creates it if not exists.
+func (b *Backend) queueLocked(queueURL string) (*queue, error) {
+	name, err := QueueName(queueURL)
+	if err != nil {
+		return nil, err
+	}
+	if q, ok := b.queues[name]; ok {
+		return q, nil
+	}
+	conf, err := parseConfig(queueURL)
+	if err != nil {
+		return nil, err
+	}
+	q := newQueue(name, conf)
+	b.queues[name] = q
+	return q, nil
+}
+
+func (b *Backend) notifyLocked() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// Stats returns the number of messages in queue.
+func (b *Backend) Stats(queueURL string) (QueueStats, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	q, err := b.queueLocked(queueURL)
+	if err != nil {
+		return QueueStats{}, err
+	}
+	now := b.now()
+	var stats QueueStats
+	for _, msg := range q.messages {
+		switch {
+		case !msg.visibleAt.After(now):
+			stats.Visible++
+		case msg.receiveCount > 0:
+			stats.NotVisible++
+		default:
+			stats.Delayed++
+		}
+	}
+	return stats, nil
+}
+
+// Queues returns names of queues.
+func (b *Backend) Queues() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	names := make([]string, 0, len(b.queues))
+	for name := range b.queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Send adds message to queue.
+func (b *Backend) Send(ctx context.Context, in sqsd.SendInput) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	q, err := b.queueLocked(in.QueueURL)
+	if err != nil {
+		return "", err
+	}
+	now := b.now()
+	if q.fifo {
+		if in.MessageGroupID == "" {
+			return "", errors.New("MessageGroupId is required for FIFO queue")
+		}
+		if in.DeduplicationID == "" {
+			return "", errors.New("MessageDeduplicationId is required for FIFO queue")
+		}
+		for id, e := range q.dedup {
+			if now.Sub(e.sentAt) >= dedupInterval {
+				delete(q.dedup, id)
+			}
+		}
+		if e, ok := q.dedup[in.DeduplicationID]; ok {
+			return e.id, nil
+		}
+	}
+	delay := in.Delay
+	if delay == 0 {
+		delay = q.conf.Delay
+	}
+	msg := &message{
+		id:              newID(),
+		body:            in.Body,
+		attributes:      in.Attributes,
+		groupID:         in.MessageGroupID,
+		deduplicationID: in.DeduplicationID,
+		sentAt:          now,
+		visibleAt:       now.Add(delay),
+	}
+	q.push(msg)
+	if q.fifo {
+		q.dedup[in.DeduplicationID] = dedupEntry{id: msg.id, sentAt: now}
+	}
+	b.notifyLocked()
+	return msg.id, nil
+}
+
+func (q *queue) push(msg *message) {
+	q.sequence++
+	msg.sequence = q.sequence
+	q.messages = append(q.messages, msg)
+}
+
+// Receive receives visible messages, and waits for them until WaitTime passes if no message is visible.
+func (b *Backend) Receive(ctx context.Context, in sqsd.ReceiveInput) ([]sqsd.Message, error) {
+	deadline := b.now().Add(in.WaitTime)
+	for {
+		b.mu.Lock()
+		q, err := b.queueLocked(in.QueueURL)
+		if err != nil {
+			b.mu.Unlock()
+			return nil, err
+		}
+		now := b.now()
+		msgs, next := b.receiveLocked(q, now, in)
+		changed := b.changed
+		b.mu.Unlock()
+
+		wait := deadline.Sub(now)
+		if len(msgs) > 0 || wait <= 0 {
+			return msgs, nil
+		}
+		if !next.IsZero() && next.Sub(now) < wait {
+			wait = next.Sub(now)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// receiveLocked picks up visible messages.
+// it also returns the earliest time when any invisible message becomes visible.
+func (b *Backend) receiveLocked(q *queue, now time.Time, in sqsd.ReceiveInput) ([]sqsd.Message, time.Time) {
+	max := in.MaxMessages
+	if max < 1 {
+		max = 1
+	}
+	visibility := in.VisibilityTimeout
+	if visibility <= 0 {
+		visibility = q.conf.VisibilityTimeout
+	}
+	var msgs []sqsd.Message
+	var next time.Time
+	// in FIFO queue, messages in group which has invisible message are blocked.
+	blocked := make(map[string]bool)
+	var moved bool
+	for i := 0; i < len(q.messages) && len(msgs) < max; i++ {
+		msg := q.messages[i]
+		if msg.visibleAt.After(now) {
+			if next.IsZero() || msg.visibleAt.Before(next) {
+				next = msg.visibleAt
+			}
+			if q.fifo {
+				blocked[msg.groupID] = true
+			}
+			continue
+		}
+		if q.fifo && blocked[msg.groupID] {
+			continue
+		}
+		if b.redriveLocked(q, msg, now) {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+			i--
+			moved = true
+			continue
+		}
+		msg.receiveCount++
+		if msg.firstReceivedAt.IsZero() {
+			msg.firstReceivedAt = now
+		}
+		msg.receipt = newID()
+		msg.visibleAt = now.Add(visibility)
+		if q.fifo {
+			// later messages in the group can be received after this message is deleted.
+			blocked[msg.groupID] = true
+		}
+		msgs = append(msgs, msg.toMessage(q, in))
+	}
+	if moved {
+		b.notifyLocked()
+	}
+	return msgs, next
+}
+
+// redriveLocked moves message to dead-letter queue if it is received more than MaxReceiveCount.
+func (b *Backend) redriveLocked(q *queue, msg *message, now time.Time) bool {
+	if q.conf.DeadLetterQueue == "" || q.conf.MaxReceiveCount < 1 || msg.receiveCount < q.conf.MaxReceiveCount {
+		return false
+	}
+	dlq, ok := b.queues[q.conf.DeadLetterQueue]
+	if !ok {
+		dlq = newQueue(q.conf.DeadLetterQueue, Config{VisibilityTimeout: 30 * time.Second})
+		b.queues[dlq.name] = dlq
+	}
+	if dlq == q {
+		return false
+	}
+	moved := *msg
+	moved.visibleAt = now
+	moved.receipt = ""
+	dlq.push(&moved)
+	return true
+}
+
+func (m *message) toMessage(q *queue, in sqsd.ReceiveInput) sqsd.Message {
+	system := map[string]string{
+		sqsd.AttributeSentTimestamp:                    strconv.FormatInt(m.sentAt.UnixMilli(), 10),
+		sqsd.AttributeApproximateReceiveCount:          strconv.Itoa(m.receiveCount),
+		sqsd.AttributeApproximateFirstReceiveTimestamp: strconv.FormatInt(m.firstReceivedAt.UnixMilli(), 10),
+	}
+	if q.fifo {
+		system[sqsd.AttributeMessageGroupID] = m.groupID
+		system[sqsd.AttributeMessageDeduplicationID] = m.deduplicationID
+		system[sqsd.AttributeSequenceNumber] = strconv.FormatInt(m.sequence, 10)
+	}
+	for name := range system {
+		if !matchName(in.SystemAttributes, name) {
+			delete(system, name)
+		}
+	}
+	attrs := make(map[string]sqsd.MessageAttribute, len(m.attributes))
+	for name, attr := range m.attributes {
+		if matchName(in.MessageAttributes, name) {
+			attrs[name] = attr
+		}
+	}
+	return sqsd.Message{
+		ID:               m.id,
+		Payload:          m.body,
+		Receipt:          m.receipt,
+		SystemAttributes: system,
+		Attributes:       attrs,
+	}
+}
+
+// matchName reports whether attribute name is requested by names.
+// "All", ".*" and "prefix.*" are accepted as well as SQS.
+func matchName(names []string, name string) bool {
+	for _, n := range names {
+		switch {
+		case n == "All" || n == ".*" || n == name:
+			return true
+		case strings.HasSuffix(n, ".*") && strings.HasPrefix(name, strings.TrimSuffix(n, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteMessages deletes messages by receipt handles.
+func (b *Backend) DeleteMessages(ctx context.Context, queueURL string, receipts []string) ([]error, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	q, err := b.queueLocked(queueURL)
+	if err != nil {
+		return nil, err
+	}
+	errs := make([]error, len(receipts))
+	for i, receipt := range receipts {
+		idx := q.indexByReceipt(receipt)
+		if idx < 0 {
+			errs[i] = &sqsd.BatchEntryError{
+				Code:        "ReceiptHandleIsInvalid",
+				Message:     "the receipt handle is not valid",
+				SenderFault: true,
+			}
+			continue
+		}
+		q.messages = append(q.messages[:idx], q.messages[idx+1:]...)
+	}
+	b.notifyLocked()
+	return errs, nil
+}
+
+// ChangeVisibility changes VisibilityTimeout of received message.
+func (b *Backend) ChangeVisibility(ctx context.Context, queueURL, receipt string, timeout time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	q, err := b.queueLocked(queueURL)
+	if err != nil {
+		return err
+	}
+	idx := q.indexByReceipt(receipt)
+	if idx < 0 {
+		return errors.New("ReceiptHandleIsInvalid: the receipt handle is not valid")
+	}
+	q.messages[idx].visibleAt = b.now().Add(timeout)
+	b.notifyLocked()
+	return nil
+}
+
+func (q *queue) indexByReceipt(receipt string) int {
+	if receipt == "" {
+		return -1
+	}
+	for i, msg := range q.messages {
+		if msg.receipt == receipt {
+			return i
+		}
+	}
+	return -1
+}
+
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
@@ -0,0 +1,278 @@
+package memorybackend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	sqsd "github.com/taiyoh/sqsd/v2"
+)
+
+func TestQueueName(t *testing.T) {
+	for _, tt := range []struct {
+		url  string
+		name string
+		ok   bool
+	}{
+		{url: "memory://jobs", name: "jobs", ok: true},
+		{url: "memory://jobs.fifo?visibility_timeout=10s", name: "jobs.fifo", ok: true},
+		{url: "http://localhost:9324/000000000000/jobs", name: "jobs", ok: true},
+		{url: "memory://jobs/foo"},
+		{url: "memory://"},
+	} {
+		name, err := QueueName(tt.url)
+		if !tt.ok {
+			assert.Error(t, err, tt.url)
+			continue
+		}
+		assert.NoError(t, err, tt.url)
+		assert.Equal(t, tt.name, name)
+	}
+}
+
+func receiveInput(queueURL string, max int) sqsd.ReceiveInput {
+	return sqsd.ReceiveInput{
+		QueueURL:          queueURL,
+		MaxMessages:       max,
+		VisibilityTimeout: 100 * time.Millisecond,
+		SystemAttributes:  []string{"All"},
+		MessageAttributes: []string{"All"},
+	}
+}
+
+func TestBackendVisibility(t *testing.T) {
+	ctx := context.Background()
+	b := New()
+	queueURL := URL("jobs")
+	id, err := b.Send(ctx, sqsd.SendInput{
+		QueueURL: queueURL,
+		Body:     "hello",
+		Attributes: map[string]sqsd.MessageAttribute{
+			"Kind":       {DataType: "String", StringValue: "user"},
+			"trace.id":   {DataType: "String", StringValue: "abc"},
+			"trace.span": {DataType: "String", StringValue: "def"},
+		},
+	})
+	assert.NoError(t, err)
+
+	msgs, err := b.Receive(ctx, receiveInput(queueURL, 10))
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, id, msgs[0].ID)
+		assert.Equal(t, "hello", msgs[0].Payload)
+		assert.Equal(t, 1, msgs[0].ReceiveCount())
+		assert.False(t, msgs[0].SentTimestamp().IsZero())
+		assert.False(t, msgs[0].FirstReceiveTimestamp().IsZero())
+		assert.Len(t, msgs[0].Attributes, 3)
+	}
+	stats, err := b.Stats(queueURL)
+	assert.NoError(t, err)
+	assert.Equal(t, QueueStats{NotVisible: 1}, stats)
+
+	// invisible message is not received.
+	msgs, err = b.Receive(ctx, receiveInput(queueURL, 10))
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	// long polling waits until visibility timeout expires.
+	in := receiveInput(queueURL, 10)
+	in.WaitTime = time.Second
+	in.SystemAttributes = []string{sqsd.AttributeApproximateReceiveCount}
+	in.MessageAttributes = []string{"trace.*"}
+	started := time.Now()
+	msgs, err = b.Receive(ctx, in)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(started), 500*time.Millisecond)
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, map[string]string{sqsd.AttributeApproximateReceiveCount: "2"}, msgs[0].SystemAttributes)
+		assert.Len(t, msgs[0].Attributes, 2)
+	}
+
+	assert.NoError(t, b.ChangeVisibility(ctx, queueURL, msgs[0].Receipt, 0))
+	msgs, err = b.Receive(ctx, receiveInput(queueURL, 10))
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, 3, msgs[0].ReceiveCount())
+	}
+
+	errs, err := b.DeleteMessages(ctx, queueURL, []string{msgs[0].Receipt, "invalid"})
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.Equal(t, &sqsd.BatchEntryError{
+		Code:        "ReceiptHandleIsInvalid",
+		Message:     "the receipt handle is not valid",
+		SenderFault: true,
+	}, errs[1])
+	stats, err = b.Stats(queueURL)
+	assert.NoError(t, err)
+	assert.Equal(t, QueueStats{}, stats)
+}
+
+func TestBackendDelay(t *testing.T) {
+	ctx := context.Background()
+	b := New()
+	queueURL := URL("jobs?delay=100ms")
+	_, err := b.Send(ctx, sqsd.SendInput{QueueURL: queueURL, Body: "default delay"})
+	assert.NoError(t, err)
+	_, err = b.Send(ctx, sqsd.SendInput{QueueURL: queueURL, Body: "delayed", Delay: time.Hour})
+	assert.NoError(t, err)
+
+	stats, err := b.Stats(queueURL)
+	assert.NoError(t, err)
+	assert.Equal(t, QueueStats{Delayed: 2}, stats)
+
+	in := receiveInput(queueURL, 10)
+	in.WaitTime = time.Second
+	msgs, err := b.Receive(ctx, in)
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, "default delay", msgs[0].Payload)
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = b.Receive(cctx, in)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBackendQueues(t *testing.T) {
+	b := New()
+	_, err := b.QueueConfig("jobs")
+	assert.ErrorIs(t, err, ErrQueueNotFound)
+	assert.ErrorIs(t, b.DeleteQueue("jobs"), ErrQueueNotFound)
+
+	assert.NoError(t, b.CreateQueue("jobs", Config{Delay: time.Second}))
+	assert.ErrorIs(t, b.CreateQueue("jobs", Config{}), ErrQueueExists)
+	conf, err := b.QueueConfig("jobs")
+	assert.NoError(t, err)
+	assert.Equal(t, Config{VisibilityTimeout: 30 * time.Second, Delay: time.Second}, conf)
+	assert.Equal(t, []string{"jobs"}, b.Queues())
+
+	assert.NoError(t, b.DeleteQueue("jobs"))
+	assert.Empty(t, b.Queues())
+}
+
+func TestBackendRedrive(t *testing.T) {
+	ctx := context.Background()
+	b := New()
+	assert.NoError(t, b.CreateQueue("jobs", Config{
+		VisibilityTimeout: time.Second,
+		DeadLetterQueue:   "jobs-dlq",
+		MaxReceiveCount:   2,
+	}))
+	assert.ErrorIs(t, b.CreateQueue("jobs", Config{}), ErrQueueExists)
+
+	queueURL := URL("jobs")
+	_, err := b.Send(ctx, sqsd.SendInput{QueueURL: queueURL, Body: "poison"})
+	assert.NoError(t, err)
+	for i := 1; i <= 2; i++ {
+		msgs, err := b.Receive(ctx, receiveInput(queueURL, 10))
+		assert.NoError(t, err)
+		if assert.Len(t, msgs, 1) {
+			assert.Equal(t, i, msgs[0].ReceiveCount())
+			assert.NoError(t, b.ChangeVisibility(ctx, queueURL, msgs[0].Receipt, 0))
+		}
+	}
+	msgs, err := b.Receive(ctx, receiveInput(queueURL, 10))
+	assert.NoError(t, err)
+	assert.Empty(t, msgs, "message is moved to dead-letter queue")
+
+	msgs, err = b.Receive(ctx, receiveInput(URL("jobs-dlq"), 10))
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, "poison", msgs[0].Payload)
+	}
+	assert.Equal(t, []string{"jobs", "jobs-dlq"}, b.Queues())
+}
+
+func TestBackendFIFO(t *testing.T) {
+	ctx := context.Background()
+	b := New()
+	queueURL := URL("jobs.fifo")
+
+	_, err := b.Send(ctx, sqsd.SendInput{QueueURL: queueURL, Body: "no group"})
+	assert.Error(t, err)
+
+	for i, group := range []string{"a", "a", "b"} {
+		_, err := b.Send(ctx, sqsd.SendInput{
+			QueueURL:        queueURL,
+			Body:            fmt.Sprintf("%s:%d", group, i),
+			MessageGroupID:  group,
+			DeduplicationID: fmt.Sprintf("dedup-%d", i),
+		})
+		assert.NoError(t, err)
+	}
+	id, err := b.Send(ctx, sqsd.SendInput{QueueURL: queueURL, Body: "dup", MessageGroupID: "a", DeduplicationID: "dedup-0"})
+	assert.NoError(t, err)
+
+	msgs, err := b.Receive(ctx, receiveInput(queueURL, 10))
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 2, "only head of each group is received") {
+		assert.Equal(t, id, msgs[0].ID, "duplicated message is not sent")
+		assert.Equal(t, "a:0", msgs[0].Payload)
+		assert.Equal(t, "a", msgs[0].MessageGroupID())
+		assert.Equal(t, "1", msgs[0].SequenceNumber())
+		assert.Equal(t, "b:2", msgs[1].Payload)
+	}
+	errs, err := b.DeleteMessages(ctx, queueURL, []string{msgs[0].Receipt})
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+
+	msgs, err = b.Receive(ctx, receiveInput(queueURL, 10))
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, "a:1", msgs[0].Payload)
+	}
+}
+
+type recordInvoker struct {
+	mu       sync.Mutex
+	payloads []string
+}
+
+func (i *recordInvoker) Invoke(ctx context.Context, msg sqsd.Message) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.payloads = append(i.payloads, msg.Payload)
+	return nil
+}
+
+func TestSystemWithBackend(t *testing.T) {
+	sqsd.SetWithHandlerOptions(slog.HandlerOptions{Level: slog.LevelWarn}, io.Discard)
+	b := New()
+	queueURL := URL("jobs")
+	for i := 0; i < 5; i++ {
+		_, err := b.Send(context.Background(), sqsd.SendInput{QueueURL: queueURL, Body: fmt.Sprintf("job-%d", i)})
+		assert.NoError(t, err)
+	}
+
+	ivk := &recordInvoker{}
+	sys := sqsd.NewSystem(
+		sqsd.BackendGatewayBuilder(b, queueURL,
+			sqsd.FetchInterval(10*time.Millisecond),
+			sqsd.FetcherWaitTime(100*time.Millisecond),
+			sqsd.RemoverBatchLinger(0)),
+		sqsd.ConsumerBuilder(ivk, 2),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- sys.Run(ctx)
+	}()
+	assert.Eventually(t, func() bool {
+		stats, err := b.Stats(queueURL)
+		return err == nil && stats == QueueStats{}
+	}, 2*time.Second, 10*time.Millisecond)
+	cancel()
+	assert.NoError(t, <-errCh)
+
+	ivk.mu.Lock()
+	assert.ElementsMatch(t, []string{"job-0", "job-1", "job-2", "job-3", "job-4"}, ivk.payloads)
+	ivk.mu.Unlock()
+}
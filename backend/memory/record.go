@@ -0,0 +1,126 @@
+package memorybackend
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	sqsd "github.com/taiyoh/sqsd/v2"
+)
+
+// Record is a message in JSON lines, which is used for seeding queue.
+// It is compatible with lines written by sqsd.FileDeadLetterSink, so that dead-lettered messages can be replayed.
+type Record struct {
+	// Queue is the name of queue. if it is empty, the default queue is used.
+	Queue             string               `json:"queue,omitempty"`
+	Body              string               `json:"body"`
+	MessageAttributes map[string]Attribute `json:"message_attributes,omitempty"`
+	DelaySeconds      int                  `json:"delay_seconds,omitempty"`
+	MessageGroupID    string               `json:"message_group_id,omitempty"`
+	DeduplicationID   string               `json:"deduplication_id,omitempty"`
+}
+
+// Attribute is a message attribute of Record.
+type Attribute struct {
+	DataType    string `json:"data_type"`
+	StringValue string `json:"string_value,omitempty"`
+	BinaryValue []byte `json:"binary_value,omitempty"`
+}
+
+func (r Record) sendInput(queueURL string) sqsd.SendInput {
+	in := sqsd.SendInput{
+		QueueURL:        queueURL,
+		Body:            r.Body,
+		Delay:           time.Duration(r.DelaySeconds) * time.Second,
+		MessageGroupID:  r.MessageGroupID,
+		DeduplicationID: r.DeduplicationID,
+	}
+	if r.Queue != "" {
+		in.QueueURL = URL(r.Queue)
+	}
+	if len(r.MessageAttributes) > 0 {
+		in.Attributes = make(map[string]sqsd.MessageAttribute, len(r.MessageAttributes))
+		for name, attr := range r.MessageAttributes {
+			in.Attributes[name] = sqsd.MessageAttribute{
+				DataType:    attr.DataType,
+				StringValue: attr.StringValue,
+				BinaryValue: attr.BinaryValue,
+			}
+		}
+	}
+	return in
+}
+
+// Load sends messages of JSON lines to queue, and returns their message ids.
+// Records which have queue name are sent to that queue instead of queueURL.
+// Empty lines are skipped.
+func (b *Backend) Load(ctx context.Context, queueURL string, r io.Reader) ([]string, error) {
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return ids, fmt.Errorf("line %d: %w", n, err)
+		}
+		id, err := b.Send(ctx, rec.sendInput(queueURL))
+		if err != nil {
+			return ids, fmt.Errorf("line %d: %w", n, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, scanner.Err()
+}
+
+// Handler returns http.Handler for enqueueing messages.
+//
+//	POST /queues/<name>  sends messages of JSON lines in request body, and responds their message ids.
+//	GET  /queues/<name>  responds QueueStats of the queue.
+//	GET  /queues         responds names of queues.
+func (b *Backend) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := strings.CutPrefix(r.URL.Path, "/queues")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		name = strings.Trim(name, "/")
+		switch {
+		case name == "" && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string][]string{"queues": b.Queues()})
+		case name == "" || strings.Contains(name, "/"):
+			http.NotFound(w, r)
+		case r.Method == http.MethodPost:
+			ids, err := b.Load(r.Context(), URL(name), r.Body)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error(), "message_ids": ids})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string][]string{"message_ids": ids})
+		case r.Method == http.MethodGet:
+			stats, err := b.Stats(URL(name))
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, stats)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
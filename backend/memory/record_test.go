@@ -0,0 +1,83 @@
+package memorybackend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackendLoad(t *testing.T) {
+	ctx := context.Background()
+	b := New()
+	ids, err := b.Load(ctx, URL("jobs"), strings.NewReader(`{"body":"first","message_attributes":{"Kind":{"data_type":"String","string_value":"user"}}}
+
+{"queue":"other","body":"second"}
+{"body":"third","delay_seconds":60,"dead_letter":{"attempts":3}}
+`))
+	assert.NoError(t, err)
+	assert.Len(t, ids, 3)
+
+	msgs, err := b.Receive(ctx, receiveInput(URL("jobs"), 10))
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, "first", msgs[0].Payload)
+		assert.Equal(t, "user", msgs[0].Attributes["Kind"].StringValue)
+	}
+	stats, err := b.Stats(URL("jobs"))
+	assert.NoError(t, err)
+	assert.Equal(t, QueueStats{NotVisible: 1, Delayed: 1}, stats)
+	stats, err = b.Stats(URL("other"))
+	assert.NoError(t, err)
+	assert.Equal(t, QueueStats{Visible: 1}, stats)
+
+	ids, err = b.Load(ctx, URL("jobs"), strings.NewReader("{\"body\":\"ok\"}\n{invalid}\n"))
+	assert.EqualError(t, err, "line 2: invalid character 'i' looking for beginning of object key string")
+	assert.Len(t, ids, 1)
+}
+
+func TestBackendHandler(t *testing.T) {
+	b := New()
+	srv := httptest.NewServer(b.Handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/queues/jobs", "application/x-ndjson", strings.NewReader("{\"body\":\"a\"}\n{\"body\":\"b\"}\n"))
+	assert.NoError(t, err)
+	var sent struct {
+		MessageIDs []string `json:"message_ids"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&sent))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, sent.MessageIDs, 2)
+
+	resp, err = http.Get(srv.URL + "/queues/jobs")
+	assert.NoError(t, err)
+	var stats QueueStats
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+	resp.Body.Close()
+	assert.Equal(t, QueueStats{Visible: 2}, stats)
+
+	resp, err = http.Get(srv.URL + "/queues")
+	assert.NoError(t, err)
+	var queues struct {
+		Queues []string `json:"queues"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&queues))
+	resp.Body.Close()
+	assert.Equal(t, []string{"jobs"}, queues.Queues)
+
+	resp, err = http.Post(srv.URL+"/queues/jobs", "application/x-ndjson", strings.NewReader("invalid"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "/other")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -2,12 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -18,6 +24,8 @@ import (
 	"github.com/taiyoh/go-typedenv"
 
 	sqsd "github.com/taiyoh/sqsd/v2"
+	memorybackend "github.com/taiyoh/sqsd/v2/backend/memory"
+	"github.com/taiyoh/sqsd/v2/devqueue"
 	"github.com/taiyoh/sqsd/v2/locker"
 	memorylocker "github.com/taiyoh/sqsd/v2/locker/memory"
 	redislocker "github.com/taiyoh/sqsd/v2/locker/redis"
@@ -26,26 +34,346 @@ import (
 type sqsdConfig struct {
 	awsConf         config.SharedConfig
 	RawURL          string
+	StatusPolicies  string
 	QueueURL        string
+	Queues          []queueConfig
 	Duration        time.Duration
 	UnlockInterval  time.Duration
 	LockExpire      time.Duration
 	FetcherWaitTime time.Duration
 	FetcherParallel int
 	InvokerParallel int
+	Heartbeat       time.Duration
+	HeartbeatMax    time.Duration
+	RetryPolicy     sqsd.RetryPolicy
+	Adaptive        sqsd.AdaptiveConcurrency
 	MonitoringPort  int
 	LogLevel        slog.Level
+	CronConfig      string
+	DeadLetter      deadLetterConfig
+	MemoryQueue     memoryQueueConfig
+	Unwrap          unwrapConfig
+	Exec            execConfig
+	Pool            poolConfig
 	RedisLocker     *redisLocker
 }
 
+type deadLetterConfig struct {
+	MaxAttempts int
+	QueueURL    string
+	File        string
+}
+
 func (c sqsdConfig) withEndpoint(o *sqs.Options) {
 	o.BaseEndpoint = &c.awsConf.BaseEndpoint
 }
 
+// queueConfig is a queue setting in QUEUE_URL.
+// QUEUE_URL accepts comma separated queues, and each queue can have options separated by semicolon.
+//
+//	https://sqs.../high;priority=1,https://sqs.../low;weight=2;max=3;invoker=http://localhost:8081
+type queueConfig struct {
+	URL            string
+	Weight         int
+	Priority       int
+	MaxConcurrency int
+	InvokerURL     string
+}
+
+func parseQueues(s string) ([]queueConfig, error) {
+	var queues []queueConfig
+	for _, expr := range strings.Split(s, ",") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		parts := strings.Split(expr, ";")
+		q := queueConfig{URL: parts[0], Weight: 1}
+		for _, opt := range parts[1:] {
+			key, val, ok := strings.Cut(opt, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid queue option: %q", opt)
+			}
+			var err error
+			switch key {
+			case "weight":
+				q.Weight, err = strconv.Atoi(val)
+			case "priority":
+				q.Priority, err = strconv.Atoi(val)
+			case "max":
+				q.MaxConcurrency, err = strconv.Atoi(val)
+			case "invoker":
+				q.InvokerURL = val
+			default:
+				err = errors.New("unknown option")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid queue option %q: %w", opt, err)
+			}
+		}
+		queues = append(queues, q)
+	}
+	if len(queues) == 0 {
+		return nil, errors.New("QUEUE_URL is empty")
+	}
+	return queues, nil
+}
+
+// unwrapConfig enables sqsd.UnwrapInvoker in front of invokers.
+// SNS signature is verified when certificate is given.
+type unwrapConfig struct {
+	Enabled          bool
+	SNSCertificate   string
+	InvokeAllRecords bool
+}
+
+func (c unwrapConfig) params() ([]sqsd.UnwrapParameter, error) {
+	var params []sqsd.UnwrapParameter
+	if c.InvokeAllRecords {
+		params = append(params, sqsd.UnwrapInvokeAllRecords())
+	}
+	if c.SNSCertificate == "" {
+		return params, nil
+	}
+	cert, err := sqsd.LoadSNSCertificate(c.SNSCertificate)
+	if err != nil {
+		return nil, err
+	}
+	return append(params, sqsd.UnwrapSNSCertificates(cert)), nil
+}
+
+// execConfig is the setting of ExecInvoker, which is used when INVOKER_URL has "exec" scheme.
+type execConfig struct {
+	ExitCodePolicies string
+	WorkDir          string
+	EnvAllowlist     *string
+	KillDelay        time.Duration
+	OutputLimit      int
+}
+
+func (c execConfig) params() ([]sqsd.ExecInvokerParameter, error) {
+	policies, err := sqsd.ParseStatusPolicies(c.ExitCodePolicies)
+	if err != nil {
+		return nil, err
+	}
+	params := []sqsd.ExecInvokerParameter{
+		sqsd.ExecExitCodePolicies(policies...),
+		sqsd.ExecWorkDir(c.WorkDir),
+		sqsd.ExecKillDelay(c.KillDelay),
+		sqsd.ExecOutputLimit(c.OutputLimit),
+	}
+	if c.EnvAllowlist != nil {
+		var names []string
+		for _, name := range strings.Split(*c.EnvAllowlist, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		params = append(params, sqsd.ExecEnvAllowlist(names...))
+	}
+	return params, nil
+}
+
+// poolConfig is the setting of ProcessPoolInvoker, which is used when INVOKER_URL has "pool" scheme.
+// Size 0 means that pool size follows INVOKER_PARALLEL_COUNT.
+type poolConfig struct {
+	CodePolicies string
+	WorkDir      string
+	Size         int
+	MaxJobs      int
+	KillDelay    time.Duration
+}
+
+func (c poolConfig) params() ([]sqsd.ProcessPoolParameter, error) {
+	policies, err := sqsd.ParseStatusPolicies(c.CodePolicies)
+	if err != nil {
+		return nil, err
+	}
+	return []sqsd.ProcessPoolParameter{
+		sqsd.ProcessPoolCodePolicies(policies...),
+		sqsd.ProcessPoolWorkDir(c.WorkDir),
+		sqsd.ProcessPoolSize(c.Size),
+		sqsd.ProcessPoolMaxJobs(c.MaxJobs),
+		sqsd.ProcessPoolKillDelay(c.KillDelay),
+	}, nil
+}
+
+type memoryQueueConfig struct {
+	Seed string
+	Addr string
+}
+
+// queueBackends selects queue backend by scheme of queue URL.
+// memory backend is created only when "memory://" queue URL is used.
+type queueBackends struct {
+	sqs    sqsd.QueueBackend
+	memory *memorybackend.Backend
+}
+
+func newQueueBackends(sqsBackend sqsd.QueueBackend) *queueBackends {
+	return &queueBackends{sqs: sqsBackend}
+}
+
+func (b *queueBackends) get(queueURL string) sqsd.QueueBackend {
+	if !strings.HasPrefix(queueURL, memorybackend.Scheme+"://") {
+		return b.sqs
+	}
+	if b.memory == nil {
+		b.memory = memorybackend.New()
+	}
+	return b.memory
+}
+
+// setupMemoryBackend seeds messages to the first memory queue, and serves endpoint for enqueueing messages.
+func setupMemoryBackend(ctx context.Context, mem *memorybackend.Backend, conf memoryQueueConfig, queues []queueConfig) error {
+	if conf.Seed != "" {
+		var queueURL string
+		for _, q := range queues {
+			if strings.HasPrefix(q.URL, memorybackend.Scheme+"://") {
+				queueURL = q.URL
+				break
+			}
+		}
+		f, err := os.Open(conf.Seed)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := mem.Load(ctx, queueURL, f); err != nil {
+			return fmt.Errorf("failed to seed memory queue: %w", err)
+		}
+	}
+	if conf.Addr != "" {
+		srv := &http.Server{
+			Addr:              conf.Addr,
+			Handler:           mem.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		l, err := net.Listen("tcp", conf.Addr)
+		if err != nil {
+			return err
+		}
+		go func() {
+			<-ctx.Done()
+			_ = srv.Close()
+		}()
+		go func() {
+			_ = srv.Serve(l)
+		}()
+	}
+	return nil
+}
+
+// newDevQueueServer returns server of "sqsd dev-queue" subcommand, which serves SQS compatible API on in-memory queues.
+// "/queues" endpoint of memory backend is also served for enqueueing messages in JSON lines.
+//
+//	sqsd dev-queue -addr :9324 -queues jobs,jobs.fifo
+func newDevQueueServer(args []string) (*http.Server, error) {
+	fs := flag.NewFlagSet("dev-queue", flag.ContinueOnError)
+	addr := fs.String("addr", ":9324", "listen address")
+	queues := fs.String("queues", "", "comma separated names of queues which are created at start")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	backend := memorybackend.New()
+	for _, name := range strings.Split(*queues, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if err := backend.CreateQueue(name, memorybackend.Config{}); err != nil {
+			return nil, fmt.Errorf("failed to create queue %s: %w", name, err)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/queues", backend.Handler())
+	mux.Handle("/queues/", backend.Handler())
+	mux.Handle("/", devqueue.New(backend))
+	return &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}, nil
+}
+
+func runDevQueue(args []string) error {
+	srv, err := newDevQueueServer(args)
+	if err != nil {
+		return err
+	}
+	logger := sqsd.NewLogger(slog.HandlerOptions{}, os.Stderr, "sqsd-dev-queue")
+
+	ctx, cancel := signal.NotifyContext(
+		context.Background(),
+		syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+	logger.Info("start dev-queue", "addr", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	logger.Info("end dev-queue")
+	return nil
+}
+
+// newInvoker returns invoker by scheme of URL.
+//
+//	http://, https://                  HTTPInvoker
+//	fastcgi://, fastcgi+unix://        FastCGIInvoker
+//	exec://                            ExecInvoker
+//	pool://                            ProcessPoolInvoker
+//	grpc://, grpc+unix://              GRPCInvoker
+func (c sqsdConfig) newInvoker(rawurl string, policies []sqsd.StatusPolicy) (sqsd.Invoker, error) {
+	scheme, _, _ := strings.Cut(rawurl, "://")
+	switch scheme {
+	case "pool":
+		params, err := c.Pool.params()
+		if err != nil {
+			return nil, err
+		}
+		ivk, err := sqsd.NewProcessPoolInvoker(rawurl, c.Duration, params...)
+		if err != nil {
+			return nil, err
+		}
+		return ivk, nil
+	case "exec":
+		params, err := c.Exec.params()
+		if err != nil {
+			return nil, err
+		}
+		ivk, err := sqsd.NewExecInvoker(rawurl, c.Duration, params...)
+		if err != nil {
+			return nil, err
+		}
+		return ivk, nil
+	case "grpc", "grpc+unix":
+		ivk, err := sqsd.NewGRPCInvoker(rawurl, c.Duration)
+		if err != nil {
+			return nil, err
+		}
+		return ivk, nil
+	case "fastcgi", "fastcgi+unix":
+		ivk, err := sqsd.NewFastCGIInvoker(rawurl, c.Duration, sqsd.FastCGIStatusPolicies(policies...), sqsd.FastCGIPoolSize(c.InvokerParallel))
+		if err != nil {
+			return nil, err
+		}
+		return ivk, nil
+	}
+	ivk, err := sqsd.NewHTTPInvoker(rawurl, c.Duration, sqsd.HTTPStatusPolicies(policies...))
+	if err != nil {
+		return nil, err
+	}
+	return ivk, nil
+}
+
 type redisLocker struct {
-	Host    string
-	DBName  int
-	KeyName string
+	Host             string
+	DBName           int
+	KeyName          string
+	ProcessingExpire time.Duration
 }
 
 func (c *sqsdConfig) Load() error {
@@ -53,24 +381,72 @@ func (c *sqsdConfig) Load() error {
 		typedenv.RequiredDirect("INVOKER_URL", &c.RawURL),
 		typedenv.RequiredDirect("QUEUE_URL", &c.QueueURL),
 		typedenv.DefaultDirect("INVOKER_TIMEOUT", &c.Duration, "60s"),
+		typedenv.DefaultDirect("INVOKER_STATUS_POLICIES", &c.StatusPolicies, ""),
 		typedenv.DefaultDirect("UNLOCK_INTERVAL", &c.UnlockInterval, "1m"),
 		typedenv.DefaultDirect("LOCK_EXPIRE", &c.LockExpire, "24h"),
 		typedenv.DefaultDirect("FETCHER_WAIT_TIME", &c.FetcherWaitTime, "1s"),
 		typedenv.DefaultDirect("FETCHER_PARALLEL_COUNT", &c.FetcherParallel, "1"),
 		typedenv.DefaultDirect("INVOKER_PARALLEL_COUNT", &c.InvokerParallel, "1"),
+		typedenv.DefaultDirect("VISIBILITY_HEARTBEAT", &c.Heartbeat, "0s"),
+		typedenv.DefaultDirect("VISIBILITY_HEARTBEAT_MAX", &c.HeartbeatMax, "12h"),
+		typedenv.DefaultDirect("RETRY_BACKOFF_BASE", &c.RetryPolicy.Base, "0s"),
+		typedenv.DefaultDirect("RETRY_BACKOFF_MULTIPLIER", &c.RetryPolicy.Multiplier, "2"),
+		typedenv.DefaultDirect("RETRY_BACKOFF_MIN", &c.RetryPolicy.Min, "0s"),
+		typedenv.DefaultDirect("RETRY_BACKOFF_MAX", &c.RetryPolicy.Max, "15m"),
+		typedenv.DefaultDirect("RETRY_BACKOFF_JITTER", &c.RetryPolicy.Jitter, "0.2"),
+		typedenv.DefaultDirect("ADAPTIVE_CONCURRENCY_MIN", &c.Adaptive.Min, "1"),
+		typedenv.DefaultDirect("ADAPTIVE_CONCURRENCY_MAX", &c.Adaptive.Max, "0"),
+		typedenv.DefaultDirect("ADAPTIVE_CONCURRENCY_INTERVAL", &c.Adaptive.Interval, "5s"),
+		typedenv.DefaultDirect("ADAPTIVE_CONCURRENCY_ERROR_RATE", &c.Adaptive.ErrorRate, "0.1"),
+		typedenv.DefaultDirect("ADAPTIVE_CONCURRENCY_TOLERANCE", &c.Adaptive.Tolerance, "2"),
+		typedenv.DefaultDirect("ADAPTIVE_CONCURRENCY_BACKOFF", &c.Adaptive.Backoff, "0.75"),
 		typedenv.DefaultDirect("MONITORING_PORT", &c.MonitoringPort, "6969"),
 		typedenv.Default("LOG_LEVEL", &c.LogLevel, "info"),
+		typedenv.DefaultDirect("CRON_CONFIG", &c.CronConfig, ""),
+		typedenv.DefaultDirect("DEAD_LETTER_MAX_ATTEMPTS", &c.DeadLetter.MaxAttempts, "0"),
+		typedenv.DefaultDirect("DEAD_LETTER_QUEUE_URL", &c.DeadLetter.QueueURL, ""),
+		typedenv.DefaultDirect("DEAD_LETTER_FILE", &c.DeadLetter.File, ""),
+		typedenv.DefaultDirect("MEMORY_QUEUE_SEED", &c.MemoryQueue.Seed, ""),
+		typedenv.DefaultDirect("MEMORY_QUEUE_ADDR", &c.MemoryQueue.Addr, ""),
+		typedenv.DefaultDirect("UNWRAP_ENVELOPE", &c.Unwrap.Enabled, "false"),
+		typedenv.DefaultDirect("UNWRAP_SNS_CERTIFICATE", &c.Unwrap.SNSCertificate, ""),
+		typedenv.DefaultDirect("UNWRAP_S3_INVOKE_ALL_RECORDS", &c.Unwrap.InvokeAllRecords, "false"),
+		typedenv.DefaultDirect("EXEC_EXIT_CODE_POLICIES", &c.Exec.ExitCodePolicies, ""),
+		typedenv.DefaultDirect("EXEC_WORK_DIR", &c.Exec.WorkDir, ""),
+		typedenv.LookupDirect("EXEC_ENV_ALLOWLIST", &c.Exec.EnvAllowlist),
+		typedenv.DefaultDirect("EXEC_KILL_DELAY", &c.Exec.KillDelay, "5s"),
+		typedenv.DefaultDirect("EXEC_OUTPUT_LIMIT", &c.Exec.OutputLimit, "4096"),
+		typedenv.DefaultDirect("POOL_CODE_POLICIES", &c.Pool.CodePolicies, ""),
+		typedenv.DefaultDirect("POOL_WORK_DIR", &c.Pool.WorkDir, ""),
+		typedenv.DefaultDirect("POOL_SIZE", &c.Pool.Size, "0"),
+		typedenv.DefaultDirect("POOL_MAX_JOBS", &c.Pool.MaxJobs, "0"),
+		typedenv.DefaultDirect("POOL_KILL_DELAY", &c.Pool.KillDelay, "5s"),
 		typedenv.DefaultDirect("AWS_REGION", &c.awsConf.Region, "ap-northeast-1"),
 		typedenv.LookupDirect("SQS_ENDPOINT_URL", &c.awsConf.BaseEndpoint),
 	); err != nil {
 		return err
 	}
 
+	if c.DeadLetter.QueueURL != "" && c.DeadLetter.File != "" {
+		return errors.New("DEAD_LETTER_QUEUE_URL and DEAD_LETTER_FILE are exclusive")
+	}
+
+	if c.Unwrap.SNSCertificate != "" {
+		c.Unwrap.Enabled = true
+	}
+
+	queues, err := parseQueues(c.QueueURL)
+	if err != nil {
+		return err
+	}
+	c.Queues = queues
+
 	var rl redisLocker
 	if err := typedenv.Scan(
 		typedenv.RequiredDirect("REDIS_LOCKER_HOST", &rl.Host),
 		typedenv.DefaultDirect("REDIS_LOCKER_DBNAME", &rl.DBName, "0"),
 		typedenv.RequiredDirect("REDIS_LOCKER_KEYNAME", &rl.KeyName),
+		typedenv.DefaultDirect("REDIS_LOCKER_PROCESSING_EXPIRE", &rl.ProcessingExpire, "5m"),
 	); err == nil {
 		c.RedisLocker = &rl
 	}
@@ -79,6 +455,13 @@ func (c *sqsdConfig) Load() error {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "dev-queue" {
+		if err := runDevQueue(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	loadEnvFromFile()
 
 	var args sqsdConfig
@@ -97,6 +480,7 @@ func main() {
 	}
 
 	queue := sqs.NewFromConfig(cfg, args.withEndpoint)
+	backends := newQueueBackends(sqsd.NewSQSBackend(queue))
 
 	var queueLocker locker.QueueLocker
 	if rl := args.RedisLocker; rl != nil {
@@ -107,7 +491,7 @@ func main() {
 		if err != nil {
 			log.Fatal(err)
 		}
-		queueLocker = redislocker.New(db, rl.KeyName)
+		queueLocker = redislocker.New(db, rl.KeyName, redislocker.ProcessingExpire(rl.ProcessingExpire))
 		logger.Info("redis queue locker is selected")
 	} else {
 		queueLocker = memorylocker.New()
@@ -119,25 +503,100 @@ func main() {
 		log.Fatal(err)
 	}
 
-	ivk, err := sqsd.NewHTTPInvoker(args.RawURL, args.Duration)
+	statusPolicies, err := sqsd.ParseStatusPolicies(args.StatusPolicies)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	ivk, err := args.newInvoker(args.RawURL, statusPolicies)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var unwrapParams []sqsd.UnwrapParameter
+	if args.Unwrap.Enabled {
+		if unwrapParams, err = args.Unwrap.params(); err != nil {
+			log.Fatal(err)
+		}
+		logger.Info("envelope of message is unwrapped", "sns_certificate", args.Unwrap.SNSCertificate, "invoke_all_records", args.Unwrap.InvokeAllRecords)
+	}
+	wrapInvoker := func(ivk sqsd.Invoker) sqsd.Invoker {
+		if !args.Unwrap.Enabled {
+			return ivk
+		}
+		return sqsd.NewUnwrapInvoker(ivk, unwrapParams...)
+	}
+
 	var maxMessages int32 = 1
 
-	sys := sqsd.NewSystem(
-		sqsd.GatewayBuilder(queue, args.QueueURL, args.FetcherParallel, args.Duration,
+	var consumerParams []sqsd.ConsumerParameter
+	if args.Heartbeat > 0 {
+		consumerParams = append(consumerParams, sqsd.ConsumerVisibilityHeartbeat(args.Heartbeat, args.HeartbeatMax))
+	}
+	if args.RetryPolicy.Base > 0 {
+		consumerParams = append(consumerParams, sqsd.ConsumerRetryPolicy(args.RetryPolicy))
+	}
+	if a := args.Adaptive; a.Max > 0 {
+		consumerParams = append(consumerParams, sqsd.ConsumerAdaptiveConcurrency(a))
+		logger.Info("adaptive concurrency is enabled", "min", a.Min, "max", a.Max, "interval", a.Interval.String(),
+			"error_rate", a.ErrorRate, "tolerance", a.Tolerance, "backoff", a.Backoff)
+	}
+	switch dl := args.DeadLetter; {
+	case dl.QueueURL != "":
+		consumerParams = append(consumerParams, sqsd.ConsumerDeadLetter(dl.MaxAttempts, sqsd.NewQueueDeadLetterSink(backends.get(dl.QueueURL), dl.QueueURL)))
+		logger.Info("dead-letter queue is configured", "url", dl.QueueURL, "max_attempts", dl.MaxAttempts)
+	case dl.File != "":
+		sink, err := sqsd.NewFileDeadLetterSink(dl.File)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer sink.Close()
+		consumerParams = append(consumerParams, sqsd.ConsumerDeadLetter(dl.MaxAttempts, sink))
+		logger.Info("dead-letter file is configured", "path", dl.File, "max_attempts", dl.MaxAttempts)
+	}
+
+	builders := []sqsd.SystemBuilder{
+		sqsd.ConsumerBuilder(wrapInvoker(ivk), args.InvokerParallel, consumerParams...),
+		sqsd.MonitorBuilder(args.MonitoringPort),
+	}
+	for _, q := range args.Queues {
+		gatewayParams := []sqsd.GatewayParameter{
 			sqsd.FetcherMaxMessages(maxMessages),
 			sqsd.FetcherWaitTime(args.FetcherWaitTime),
-			sqsd.FetcherQueueLocker(queueLocker)),
-		sqsd.ConsumerBuilder(ivk, args.InvokerParallel),
-		sqsd.MonitorBuilder(args.MonitoringPort),
-	)
+			sqsd.FetcherQueueLocker(queueLocker),
+			sqsd.GatewayWeight(q.Weight),
+			sqsd.GatewayPriority(q.Priority),
+			sqsd.GatewayMaxConcurrency(q.MaxConcurrency),
+		}
+		if q.InvokerURL != "" {
+			qivk, err := args.newInvoker(q.InvokerURL, statusPolicies)
+			if err != nil {
+				log.Fatal(err)
+			}
+			gatewayParams = append(gatewayParams, sqsd.GatewayInvoker(wrapInvoker(qivk)))
+		}
+		builders = append(builders, sqsd.BackendGatewayBuilder(backends.get(q.URL), q.URL, gatewayParams...))
+		logger.Info("queue settings", "url", q.URL, "parallel", args.FetcherParallel, "wait_time", args.FetcherWaitTime.String(), "max_messages", maxMessages,
+			"weight", q.Weight, "priority", q.Priority, "max_concurrency", q.MaxConcurrency, "invoker_url", q.InvokerURL)
+	}
+
+	if args.CronConfig != "" {
+		tasks, err := sqsd.LoadPeriodicTasks(args.CronConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+		scheduler, err := sqsd.NewScheduler(tasks, sqsd.SchedulerQueueLocker(queueLocker))
+		if err != nil {
+			log.Fatal(err)
+		}
+		builders = append(builders, sqsd.SchedulerBuilder(scheduler))
+		logger.Info("periodic tasks are loaded", "path", args.CronConfig, "length", len(tasks))
+	}
+
+	sys := sqsd.NewSystem(builders...)
 
 	logger.Info("start process")
-	logger.Info("queue settings", "url", args.QueueURL, "parallel", args.FetcherParallel, "wait_time", args.FetcherWaitTime.String(), "max_messages", maxMessages)
-	logger.Info("invoker settings", "url", args.RawURL, "parallel", args.InvokerParallel, "timeout", args.Duration.String())
+	logger.Info("invoker settings", "url", args.RawURL, "parallel", args.InvokerParallel, "timeout", args.Duration.String(), "heartbeat", args.Heartbeat.String())
 
 	ctx, cancel := signal.NotifyContext(
 		context.Background(),
@@ -146,6 +605,13 @@ func main() {
 
 	go unlocker.Run(ctx)
 
+	if mem := backends.memory; mem != nil {
+		if err := setupMemoryBackend(ctx, mem, args.MemoryQueue, args.Queues); err != nil {
+			log.Fatal(err)
+		}
+		logger.Info("memory queue backend is selected", "seed", args.MemoryQueue.Seed, "addr", args.MemoryQueue.Addr)
+	}
+
 	if err := sys.Run(ctx); err != nil {
 		log.Fatal(err)
 	}
@@ -1,9 +1,20 @@
 package main
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	sqsd "github.com/taiyoh/sqsd/v2"
 )
 
 func TestConfigWithoutRedisLocker(t *testing.T) {
@@ -15,6 +26,44 @@ func TestConfigWithoutRedisLocker(t *testing.T) {
 	assert.Nil(t, conf.RedisLocker)
 }
 
+func TestConfigRetryPolicy(t *testing.T) {
+	var conf sqsdConfig
+	t.Setenv("INVOKER_URL", "http://localhost:8080")
+	t.Setenv("QUEUE_URL", "http://localhost:8080")
+	t.Setenv("RETRY_BACKOFF_BASE", "5s")
+	t.Setenv("RETRY_BACKOFF_JITTER", "0.5")
+
+	assert.NoError(t, conf.Load())
+	assert.Equal(t, sqsd.RetryPolicy{
+		Base:       5 * time.Second,
+		Multiplier: 2,
+		Max:        15 * time.Minute,
+		Jitter:     0.5,
+	}, conf.RetryPolicy)
+}
+
+func TestConfigAdaptiveConcurrency(t *testing.T) {
+	var conf sqsdConfig
+	t.Setenv("INVOKER_URL", "http://localhost:8080")
+	t.Setenv("QUEUE_URL", "http://localhost:8080")
+
+	assert.NoError(t, conf.Load())
+	assert.Zero(t, conf.Adaptive.Max, "disabled by default")
+
+	t.Setenv("ADAPTIVE_CONCURRENCY_MIN", "2")
+	t.Setenv("ADAPTIVE_CONCURRENCY_MAX", "32")
+	t.Setenv("ADAPTIVE_CONCURRENCY_ERROR_RATE", "0.05")
+	assert.NoError(t, conf.Load())
+	assert.Equal(t, sqsd.AdaptiveConcurrency{
+		Min:       2,
+		Max:       32,
+		Interval:  5 * time.Second,
+		ErrorRate: 0.05,
+		Tolerance: 2,
+		Backoff:   0.75,
+	}, conf.Adaptive)
+}
+
 func TestConfigWithRedisLocker(t *testing.T) {
 	t.Setenv("INVOKER_URL", "http://localhost:8080")
 	t.Setenv("QUEUE_URL", "http://localhost:8080")
@@ -32,9 +81,170 @@ func TestConfigWithRedisLocker(t *testing.T) {
 		t.Setenv("REDIS_LOCKER_KEYNAME", "hogefuga")
 		assert.NoError(t, conf.Load())
 		assert.Equal(t, redisLocker{
-			Host:    "localhost:6739",
-			DBName:  3,
-			KeyName: "hogefuga",
+			Host:             "localhost:6739",
+			DBName:           3,
+			KeyName:          "hogefuga",
+			ProcessingExpire: 5 * time.Minute,
 		}, *conf.RedisLocker)
 	})
 }
+
+func TestConfigQueues(t *testing.T) {
+	var conf sqsdConfig
+	t.Setenv("INVOKER_URL", "http://localhost:8080")
+	t.Setenv("QUEUE_URL", "http://localhost:9324/queue/high;priority=1, http://localhost:9324/queue/low;weight=2;max=3;invoker=http://localhost:8081/low")
+
+	assert.NoError(t, conf.Load())
+	assert.Equal(t, []queueConfig{
+		{URL: "http://localhost:9324/queue/high", Weight: 1, Priority: 1},
+		{URL: "http://localhost:9324/queue/low", Weight: 2, MaxConcurrency: 3, InvokerURL: "http://localhost:8081/low"},
+	}, conf.Queues)
+
+	t.Setenv("QUEUE_URL", "http://localhost:9324/queue/high;unknown=1")
+	assert.Error(t, conf.Load())
+
+	t.Setenv("QUEUE_URL", "http://localhost:9324/queue/high;weight=x")
+	assert.Error(t, conf.Load())
+}
+
+func TestConfigUnwrap(t *testing.T) {
+	var conf sqsdConfig
+	t.Setenv("INVOKER_URL", "http://localhost:8080")
+	t.Setenv("QUEUE_URL", "http://localhost:8080")
+
+	assert.NoError(t, conf.Load())
+	assert.False(t, conf.Unwrap.Enabled)
+	params, err := conf.Unwrap.params()
+	assert.NoError(t, err)
+	assert.Empty(t, params)
+
+	t.Setenv("UNWRAP_S3_INVOKE_ALL_RECORDS", "true")
+	assert.NoError(t, conf.Load())
+	params, err = conf.Unwrap.params()
+	assert.NoError(t, err)
+	assert.Len(t, params, 1)
+
+	t.Setenv("UNWRAP_SNS_CERTIFICATE", filepath.Join(t.TempDir(), "sns.pem"))
+	assert.NoError(t, conf.Load())
+	assert.True(t, conf.Unwrap.Enabled)
+	_, err = conf.Unwrap.params()
+	assert.Error(t, err)
+}
+
+func TestNewInvoker(t *testing.T) {
+	conf := sqsdConfig{Duration: time.Second, InvokerParallel: 2}
+	for rawurl, expected := range map[string]sqsd.Invoker{
+		"http://localhost:8080/run":                           &sqsd.HTTPInvoker{},
+		"fastcgi://127.0.0.1:9000/var/www/worker.php":         &sqsd.FastCGIInvoker{},
+		"fastcgi+unix:///run/php-fpm.sock?script=/worker.php": &sqsd.FastCGIInvoker{},
+		"exec:///usr/local/bin/worker?arg=jobs":               &sqsd.ExecInvoker{},
+		"pool:///usr/local/bin/worker?arg=jobs":               &sqsd.ProcessPoolInvoker{},
+		"grpc://127.0.0.1:50051":                              &sqsd.GRPCInvoker{},
+		"grpc+unix:///run/worker.sock":                        &sqsd.GRPCInvoker{},
+	} {
+		ivk, err := conf.newInvoker(rawurl, nil)
+		assert.NoError(t, err, rawurl)
+		assert.IsType(t, expected, ivk, rawurl)
+	}
+	_, err := conf.newInvoker("fastcgi://127.0.0.1:9000", nil)
+	assert.Error(t, err)
+
+	conf.Exec.ExitCodePolicies = "75:unknown"
+	_, err = conf.newInvoker("exec:///usr/local/bin/worker", nil)
+	assert.Error(t, err)
+
+	conf.Pool.CodePolicies = "75:unknown"
+	_, err = conf.newInvoker("pool:///usr/local/bin/worker", nil)
+	assert.Error(t, err)
+}
+
+func TestConfigPool(t *testing.T) {
+	var conf sqsdConfig
+	t.Setenv("INVOKER_URL", "pool:///usr/local/bin/worker")
+	t.Setenv("QUEUE_URL", "http://localhost:8080")
+
+	assert.NoError(t, conf.Load())
+	assert.Equal(t, poolConfig{KillDelay: 5 * time.Second}, conf.Pool)
+
+	t.Setenv("POOL_SIZE", "4")
+	t.Setenv("POOL_MAX_JOBS", "100")
+	t.Setenv("POOL_CODE_POLICIES", "75:retry:30s,3:retain")
+	assert.NoError(t, conf.Load())
+	assert.Equal(t, 4, conf.Pool.Size)
+	assert.Equal(t, 100, conf.Pool.MaxJobs)
+	params, err := conf.Pool.params()
+	assert.NoError(t, err)
+	assert.Len(t, params, 5)
+}
+
+func TestConfigExec(t *testing.T) {
+	var conf sqsdConfig
+	t.Setenv("INVOKER_URL", "exec:///usr/local/bin/worker")
+	t.Setenv("QUEUE_URL", "http://localhost:8080")
+
+	assert.NoError(t, conf.Load())
+	assert.Equal(t, execConfig{KillDelay: 5 * time.Second, OutputLimit: 4096}, conf.Exec)
+
+	t.Setenv("EXEC_ENV_ALLOWLIST", "PATH, HOME")
+	t.Setenv("EXEC_EXIT_CODE_POLICIES", "75:retry:30s,3:retain")
+	assert.NoError(t, conf.Load())
+	if assert.NotNil(t, conf.Exec.EnvAllowlist) {
+		assert.Equal(t, "PATH, HOME", *conf.Exec.EnvAllowlist)
+	}
+	params, err := conf.Exec.params()
+	assert.NoError(t, err)
+	assert.Len(t, params, 5)
+}
+
+func TestQueueBackends(t *testing.T) {
+	sqsBackend := sqsd.NewSQSBackend(nil)
+	backends := newQueueBackends(sqsBackend)
+	assert.Equal(t, sqsBackend, backends.get("https://sqs.ap-northeast-1.amazonaws.com/123456789012/jobs"))
+	assert.Nil(t, backends.memory)
+
+	mem := backends.get("memory://jobs")
+	assert.NotNil(t, backends.memory)
+	assert.Equal(t, mem, backends.get("memory://other"))
+
+	seed := filepath.Join(t.TempDir(), "seed.jsonl")
+	assert.NoError(t, os.WriteFile(seed, []byte("{\"body\":\"a\"}\n{\"body\":\"b\"}\n"), 0o644))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, setupMemoryBackend(ctx, backends.memory, memoryQueueConfig{Seed: seed}, []queueConfig{
+		{URL: "https://sqs.ap-northeast-1.amazonaws.com/123456789012/jobs"},
+		{URL: "memory://jobs"},
+	}))
+	stats, err := backends.memory.Stats("memory://jobs")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Visible)
+}
+
+func TestDevQueueServer(t *testing.T) {
+	_, err := newDevQueueServer([]string{"-unknown"})
+	assert.Error(t, err)
+
+	srv, err := newDevQueueServer([]string{"-addr", "127.0.0.1:19324", "-queues", "jobs, jobs.fifo"})
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:19324", srv.Addr)
+
+	ts := httptest.NewServer(srv.Handler)
+	defer ts.Close()
+
+	res, err := http.PostForm(ts.URL+"/", url.Values{"Action": {"ListQueues"}})
+	assert.NoError(t, err)
+	b, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, string(b), ts.URL+"/000000000000/jobs.fifo")
+
+	res, err = http.Post(ts.URL+"/queues/jobs", "application/x-ndjson", strings.NewReader("{\"body\":\"a\"}\n"))
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = http.Get(ts.URL + "/queues")
+	assert.NoError(t, err)
+	b, _ = io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.JSONEq(t, `{"queues":["jobs","jobs.fifo"]}`, string(b))
+}
@@ -5,36 +5,106 @@ import (
 	"errors"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"golang.org/x/sync/semaphore"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/taiyoh/sqsd/v2/locker"
 )
 
-// Message provides transition from sqs.Message
-type Message struct {
-	ID         string
-	Payload    string
-	Receipt    string
-	ReceivedAt time.Time
+type worker struct {
+	workings sync.Map
+	invoker  Invoker
+	slots    *slots
+	params   consumerParams
+	op       queueOperator
+	groups   *fifoGroups
+	pools    []*ProcessPoolInvoker
+	panics   atomic.Int64
+	state    consumerState
+	// resizeMu serializes changes of slots and runners.
+	resizeMu sync.Mutex
+	// runners holds stop channels of goroutines which process messages.
+	runners  []chan struct{}
+	startRun func(stop chan struct{})
+	// dispatched passes messages from dispatcher to runners.
+	dispatched chan Message
+	broker     chan Message
+	// dispatcherDone is closed when dispatcher finishes giving back messages after ctx is done.
+	dispatcherDone chan struct{}
+	// limiter is set if adaptive concurrency is enabled.
+	limiter *adaptiveLimiter
 }
 
-type worker struct {
-	workings  sync.Map
-	invoker   Invoker
-	semaphore *semaphore.Weighted
+type consumerParams struct {
+	visibilityExtension time.Duration
+	maxVisibility       time.Duration
+	retryPolicy         *RetryPolicy
+	maxAttempts         int
+	deadLetterSink      DeadLetterSink
+	middlewares         []Middleware
+	adaptive            *AdaptiveConcurrency
 }
 
-func startWorker(ctx context.Context, ivk Invoker, broker chan Message, rm remover) *worker {
+// ConsumerParameter sets parameter to consumer by functional option pattern.
+type ConsumerParameter func(*consumerParams)
+
+// minVisibilityExtension is the lower limit of extension by heartbeat.
+// VisibilityTimeout of SQS is set in whole seconds, and heartbeat runs at intervals of half of extension.
+const minVisibilityExtension = 2 * time.Second
+
+// ConsumerVisibilityHeartbeat makes worker extend VisibilityTimeout of invoking message.
+// While invoker is running, worker sets VisibilityTimeout to ext at intervals of half of ext,
+// until total visibility duration from receiving message reaches to max.
+// If VisibilityTimeout of receiving is shorter than ext, the first extension is at half of it since receiving.
+// VisibilityTimeout is truncated to whole seconds, so ext is 2 seconds at least,
+// and extension stops when less than 1 second is left to max.
+// max is limited to 12 hours because of SQS restriction.
+func ConsumerVisibilityHeartbeat(ext, max time.Duration) ConsumerParameter {
+	if ext < minVisibilityExtension {
+		ext = minVisibilityExtension
+	}
+	if max > maxVisibilityTimeout {
+		max = maxVisibilityTimeout
+	}
+	return func(p *consumerParams) {
+		p.visibilityExtension = ext
+		p.maxVisibility = max
+	}
+}
+
+func startWorker(ctx context.Context, ivk Invoker, broker chan Message, op queueOperator, params ...ConsumerParameter) *worker {
 	capacity := cap(broker)
 	w := &worker{
-		invoker:   ivk,
-		semaphore: semaphore.NewWeighted(int64(capacity)),
+		slots:  newSlots(capacity),
+		op:     op,
+		groups: newFIFOGroups(),
+		pools:  processPools(ivk),
+
+		dispatched:     make(chan Message),
+		broker:         broker,
+		dispatcherDone: make(chan struct{}),
+	}
+	for _, fn := range params {
+		fn(&w.params)
 	}
-	for i := 0; i < capacity; i++ {
-		go w.RunForProcess(ctx, broker, rm)
+	w.invoker = ChainMiddlewares(ivk, w.params.middlewares...)
+	w.state.set(ConsumerState_CONSUMER_STATE_RUNNING)
+	w.startRun = func(stop chan struct{}) {
+		go w.RunForProcess(ctx, stop, op)
+	}
+	go w.dispatch(ctx, broker, op)
+	if c := w.params.adaptive; c != nil {
+		conf := c.withDefaults(capacity)
+		w.limiter = newAdaptiveLimiter(conf)
+		capacity = conf.clamp(capacity)
+		w.slots.resize(capacity)
+	}
+	w.resizeRunners(capacity)
+	if w.limiter != nil {
+		go w.runLimiter(ctx)
 	}
 
 	return w
@@ -51,6 +121,16 @@ func (tasks *taskList) Slice(i, j int) bool {
 	return (*tasks)[i].StartedAt.AsTime().Before((*tasks)[j].StartedAt.AsTime())
 }
 
+// Stats returns statistics of worker.
+func (w *worker) Stats(ctx context.Context) *StatsResponse {
+	stats := &StatsResponse{}
+	if c, ok := w.op.(deleteCounter); ok {
+		stats.InflightDeletes = c.inflightDeletes()
+	}
+	stats.Panics = w.panics.Load()
+	return stats
+}
+
 // CurrentWorkings returns tasks which are invoked.
 func (w *worker) CurrentWorkings(ctx context.Context) []*Task {
 	var tasks taskList
@@ -59,54 +139,291 @@ func (w *worker) CurrentWorkings(ctx context.Context) []*Task {
 	return tasks
 }
 
-type remover interface {
+// Processes returns states of worker processes of ProcessPoolInvoker.
+func (w *worker) Processes(ctx context.Context) []*WorkerProcess {
+	var procs []*WorkerProcess
+	for _, p := range w.pools {
+		procs = append(procs, p.Processes()...)
+	}
+	return procs
+}
+
+// deleteCounter is implemented by queueOperator which deletes messages asynchronously.
+type deleteCounter interface {
+	inflightDeletes() int64
+}
+
+type queueOperator interface {
 	remove(ctx context.Context, msg Message) error
+	release(ctx context.Context, msg Message) error
+	changeVisibility(ctx context.Context, msg Message, timeout time.Duration) error
 }
 
 // ErrRetainMessage shows that this message should keep in queue.
 // So, this error means that worker must not to remove message.
 var ErrRetainMessage = errors.New("this message should be retained")
 
-func (w *worker) wrappedProcess(msg Message, rm remover) {
+// wrappedProcess invokes message and handles the result.
+// it returns true if message is completed, and false if message is left in queue.
+func (w *worker) wrappedProcess(msg Message, op queueOperator) bool {
 	ctx := context.Background()
 
-	// error never be returned because always this method receives new context object.
-	_ = w.semaphore.Acquire(ctx, 1)
-	defer w.semaphore.Release(1)
+	// slot is reserved by fetcher before sending message to broker.
+	defer w.slots.release(msg.QueueURL, 1)
 
+	startedAt := time.Now()
 	w.workings.Store(msg.ID, &Task{
-		Id:        msg.ID,
-		Receipt:   msg.Receipt,
-		StartedAt: timestamppb.New(time.Now()),
+		Id:             msg.ID,
+		Receipt:        msg.Receipt,
+		StartedAt:      timestamppb.New(startedAt),
+		MessageGroupId: msg.MessageGroupID(),
+		QueueUrl:       msg.QueueURL,
 	})
 	defer w.workings.Delete(msg.ID)
 
 	logger := getLogger().With("message_id", msg.ID)
 	logger.Debug("start to invoke.")
-	switch err := w.invoker.Invoke(ctx, msg); err {
-	case nil:
+	stopHeartbeat := w.startHeartbeat(ctx, msg, op, startedAt)
+	err := w.invoke(ctx, msg)
+	stopHeartbeat()
+
+	switch {
+	case err == nil:
 		logger.Debug("succeeded to invoke.")
-		if err := rm.remove(ctx, msg); err != nil {
+		if err := op.remove(ctx, msg); err != nil {
 			logger.Warn("failed to remove message", "error", err)
+			return false
 		}
-	case locker.ErrQueueExists:
+		return true
+	case errors.Is(err, locker.ErrQueueExists):
 		logger.Warn("received message is duplicated")
-	case ErrRetainMessage:
+		return true
+	case errors.Is(err, ErrRetainMessage):
 		logger.Info("received message should be retained")
+		w.release(ctx, msg, op)
+	case w.params.shouldDeadLetter(msg, err):
+		logger.Error("message is given up and forwarded to dead-letter sink", "error", err, "receive_count", msg.ReceiveCount())
+		if w.deadLetter(ctx, msg, op, err) {
+			return true
+		}
+		w.retry(ctx, msg, op, err)
+		w.release(ctx, msg, op)
+	case errors.Is(err, ErrDeadLetter):
+		// message is left to redrive policy of the queue.
+		logger.Error("received message should be dead-lettered", "error", err)
+		w.release(ctx, msg, op)
 	default:
 		logger.Error("failed to invoke.", "error", err)
+		w.retry(ctx, msg, op, err)
+		w.release(ctx, msg, op)
+	}
+	return false
+}
+
+// invoke calls invoker, and recovers its panic as PanicError,
+// so that the panic is handled as failure of the message, without stopping other tasks.
+// If adaptive concurrency is enabled, its latency and error are observed by limiter.
+func (w *worker) invoke(ctx context.Context, msg Message) (err error) {
+	if w.limiter != nil {
+		w.limiter.begin()
+		startedAt := time.Now()
+		defer func() { w.limiter.end(time.Since(startedAt), err) }()
+	}
+	err = recoverInvoke(ctx, w.invoker, msg)
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		w.panics.Add(1)
+	}
+	return err
+}
+
+// deadLetter forwards message to dead-letter sink and removes it from queue.
+// it returns true if message is removed.
+func (w *worker) deadLetter(ctx context.Context, msg Message, op queueOperator, err error) bool {
+	logger := getLogger().With("message_id", msg.ID)
+	if err := w.params.deadLetterSink.Put(ctx, newDeadLetterRecord(msg, err)); err != nil {
+		logger.Error("failed to forward message to dead-letter sink", "error", err)
+		return false
+	}
+	if err := op.remove(ctx, msg); err != nil {
+		logger.Warn("failed to remove dead-lettered message", "error", err)
+		return false
+	}
+	return true
+}
+
+// retry changes VisibilityTimeout of failed message to make it redelivered after backoff.
+func (w *worker) retry(ctx context.Context, msg Message, op queueOperator, err error) {
+	d, ok := w.params.retryDelay(msg, err)
+	if !ok {
+		return
+	}
+	logger := getLogger().With("message_id", msg.ID)
+	if err := op.changeVisibility(ctx, msg, d); err != nil {
+		logger.Error("failed to change visibility timeout for retry", "error", err)
+		return
+	}
+	logger.Info("message will be retried.", "delay", d.String(), "receive_count", msg.ReceiveCount())
+}
+
+// release releases lock of message which is not removed, so that it can be retried.
+func (w *worker) release(ctx context.Context, msg Message, op queueOperator) {
+	if err := op.release(ctx, msg); err != nil {
+		getLogger().Error("failed to release lock", "message_id", msg.ID, "error", err)
+	}
+}
+
+// startHeartbeat starts extending VisibilityTimeout of message if heartbeat is enabled.
+// returned function stops it and waits until it finishes.
+func (w *worker) startHeartbeat(ctx context.Context, msg Message, op queueOperator, startedAt time.Time) func() {
+	if w.params.visibilityExtension <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.keepVisibility(ctx, msg, op, startedAt)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// keepVisibility extends VisibilityTimeout of message periodically until ctx is cancelled.
+func (w *worker) keepVisibility(ctx context.Context, msg Message, op queueOperator, startedAt time.Time) {
+	ext := w.params.visibilityExtension
+	receivedAt := msg.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = startedAt
+	}
+	deadline := receivedAt.Add(w.params.maxVisibility)
+
+	// message becomes visible again when VisibilityTimeout of receiving passes, which may be shorter than ext.
+	first := ext
+	if v := msg.VisibilityTimeout; v > 0 && v < first {
+		first = v
+	}
+
+	logger := getLogger().With("message_id", msg.ID)
+	timer := time.NewTimer(max(time.Until(receivedAt.Add(first/2)), 0))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-timer.C:
+			timer.Reset(ext / 2)
+			timeout := ext
+			if rest := deadline.Sub(now); rest < timeout {
+				timeout = rest
+			}
+			// VisibilityTimeout of 0 makes running message visible immediately.
+			timeout = timeout.Truncate(time.Second)
+			if timeout <= 0 {
+				logger.Warn("visibility timeout reached to max duration.")
+				return
+			}
+			if err := op.changeVisibility(ctx, msg, timeout); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				logger.Warn("failed to extend visibility timeout", "error", err)
+				continue
+			}
+			logger.Debug("extended visibility timeout.", "timeout", timeout.String())
+			w.markExtended(msg.ID, now)
+		}
 	}
 }
 
-func (w *worker) RunForProcess(ctx context.Context, broker chan Message, rm remover) {
+func (w *worker) markExtended(id string, at time.Time) {
+	v, ok := w.workings.Load(id)
+	if !ok {
+		return
+	}
+	// Task object may be read by CurrentWorkings concurrently, so replace it by updated copy.
+	task := proto.Clone(v.(*Task)).(*Task)
+	task.ExtensionCount++
+	task.LastExtendedAt = timestamppb.New(at)
+	w.workings.Store(id, task)
+}
+
+// dispatch receives messages from broker one by one and hands them to runners, until ctx is done or broker is closed.
+// After ctx is done, it gives back messages which are not started, until broker is closed.
+// Messages of FIFO queue are added to their groups here, so that they keep received order in the group.
+func (w *worker) dispatch(ctx context.Context, broker chan Message, op queueOperator) {
+	defer close(w.dispatcherDone)
 	for {
 		select {
 		case <-ctx.Done():
+			w.giveBackOnShutdown(broker, op)
 			return
 		case msg, ok := <-broker:
-			if ok {
-				w.wrappedProcess(msg, rm)
+			if !ok {
+				return
+			}
+			if w.state.get() == ConsumerState_CONSUMER_STATE_DRAINING {
+				w.giveBack(msg, op)
+				continue
+			}
+			if w.isFIFO(msg, op) && !w.groups.enqueue(groupKeyOf(msg), msg) {
+				// runner of the group processes it after preceding messages.
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				w.giveBackDispatched(msg, op)
+				w.giveBackOnShutdown(broker, op)
+				return
+			case w.dispatched <- msg:
+			}
+		}
+	}
+}
+
+// giveBackOnShutdown gives back messages which are not started yet, so that they are redelivered soon without locks.
+// it gives back messages in broker until broker is closed after fetchers stop,
+// and then messages waiting for preceding message in FIFO group.
+func (w *worker) giveBackOnShutdown(broker chan Message, op queueOperator) {
+	for msg := range broker {
+		w.giveBack(msg, op)
+	}
+	for _, msg := range w.groups.takePending() {
+		w.giveBack(msg, op)
+	}
+}
+
+// giveBackDispatched gives back msg which is not started by runner, and following messages in its group in FIFO mode.
+func (w *worker) giveBackDispatched(msg Message, op queueOperator) {
+	w.giveBack(msg, op)
+	if w.isFIFO(msg, op) {
+		for _, m := range w.groups.drop(groupKeyOf(msg)) {
+			w.giveBack(m, op)
+		}
+	}
+}
+
+// RunForProcess processes messages from dispatcher until ctx is done or stop is closed.
+func (w *worker) RunForProcess(ctx context.Context, stop chan struct{}, op queueOperator) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case msg := <-w.dispatched:
+			if ctx.Err() != nil || w.state.get() == ConsumerState_CONSUMER_STATE_DRAINING {
+				// message is held by dispatcher while Drain or shutdown.
+				w.giveBackDispatched(msg, op)
+				continue
+			}
+			if w.isFIFO(msg, op) {
+				w.processGroup(ctx, msg, op)
+				continue
 			}
+			w.wrappedProcess(msg, op)
 		}
 	}
 }
@@ -2,13 +2,19 @@ package sqsd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/taiyoh/sqsd/v2/locker"
+	memorylocker "github.com/taiyoh/sqsd/v2/locker/memory"
 )
 
 type testInvoker func(context.Context, Message) error
@@ -29,7 +35,7 @@ func TestWorker(t *testing.T) {
 	}
 
 	broker := make(chan Message, 3)
-	w := startWorker(ctx, testInvoker(testInvokerFn), broker, &Gateway{})
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, NewGateway(newTestQueueBackend(), ""))
 	msgs := make([]Message, 0, 10)
 	for i := 1; i <= 10; i++ {
 		msgs = append(msgs, Message{
@@ -61,3 +67,260 @@ func TestWorker(t *testing.T) {
 		time.Sleep(100 * time.Millisecond)
 	}
 }
+
+type testQueueOperator struct {
+	mu       sync.Mutex
+	removed  []string
+	released []string
+	timeouts []time.Duration
+	fifo     bool
+}
+
+func (o *testQueueOperator) isFIFO(Message) bool {
+	return o.fifo
+}
+
+func (o *testQueueOperator) release(_ context.Context, msg Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.released = append(o.released, msg.ID)
+	return nil
+}
+
+func (o *testQueueOperator) remove(_ context.Context, msg Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.removed = append(o.removed, msg.ID)
+	return nil
+}
+
+func (o *testQueueOperator) changeVisibility(_ context.Context, _ Message, timeout time.Duration) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.timeouts = append(o.timeouts, timeout)
+	return nil
+}
+
+func TestWorkerVisibilityHeartbeat(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	nextCh := make(chan struct{})
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		<-nextCh
+		return nil
+	}
+
+	op := &testQueueOperator{}
+	broker := make(chan Message, 1)
+	// ext is raised to 2s.
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, op,
+		ConsumerVisibilityHeartbeat(100*time.Millisecond, 3500*time.Millisecond))
+
+	broker <- Message{ID: "id:1", ReceivedAt: time.Now()}
+	time.Sleep(2500 * time.Millisecond)
+
+	tasks := w.CurrentWorkings(ctx)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, int32(2), tasks[0].GetExtensionCount())
+		assert.NotNil(t, tasks[0].GetLastExtendedAt())
+	}
+
+	// timeout is truncated to whole seconds, and extension stops when less than 1 second is left.
+	time.Sleep(1500 * time.Millisecond)
+	op.mu.Lock()
+	assert.Equal(t, []time.Duration{2 * time.Second, time.Second}, op.timeouts)
+	op.mu.Unlock()
+
+	close(nextCh)
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, w.CurrentWorkings(ctx))
+	op.mu.Lock()
+	assert.Equal(t, []string{"id:1"}, op.removed)
+	op.mu.Unlock()
+}
+
+func TestWorkerVisibilityHeartbeatBeforeReceiveTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	nextCh := make(chan struct{})
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		<-nextCh
+		return nil
+	}
+
+	op := &testQueueOperator{}
+	broker := make(chan Message, 1)
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, op,
+		ConsumerVisibilityHeartbeat(10*time.Second, time.Hour))
+
+	// message becomes visible again after 2s, before half of ext.
+	broker <- Message{ID: "id:1", ReceivedAt: time.Now(), VisibilityTimeout: 2 * time.Second}
+	time.Sleep(1500 * time.Millisecond)
+
+	tasks := w.CurrentWorkings(ctx)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, int32(1), tasks[0].GetExtensionCount())
+	}
+	op.mu.Lock()
+	assert.Equal(t, []time.Duration{10 * time.Second}, op.timeouts)
+	op.mu.Unlock()
+
+	close(nextCh)
+}
+
+func TestWorkerGivesBackOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nextCh := make(chan struct{})
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		<-nextCh
+		return nil
+	}
+
+	backend := newTestQueueBackend()
+	l := memorylocker.New()
+	g := NewGateway(backend, fifoQueueURL, FetcherQueueLocker(l))
+	broker := make(chan Message, 1)
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, gatewayRouter{fifoQueueURL: g})
+
+	// a:1 is running, a:2 and a:3 wait for a:1, b:1 is held by dispatcher, and b:2 is buffered in broker.
+	for _, id := range []string{"a:1", "a:2", "a:3", "b:1", "b:2"} {
+		backend.push(fifoMessage(id, id[:1]))
+	}
+	msgs, err := backend.Receive(ctx, ReceiveInput{MaxMessages: 10})
+	require.NoError(t, err)
+	receipts := make(map[string]string, len(msgs))
+	for _, msg := range msgs {
+		require.NoError(t, l.Lock(ctx, msg.ID))
+		receipts[msg.ID] = msg.Receipt
+		broker <- msg
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	// broker is closed after fetchers stop.
+	close(broker)
+	close(nextCh)
+	select {
+	case <-w.dispatcherDone:
+	case <-time.After(time.Second):
+		t.Fatal("dispatcher does not finish after ctx is done")
+	}
+	require.Eventually(t, func() bool {
+		return len(w.CurrentWorkings(ctx)) == 0
+	}, time.Second, 10*time.Millisecond, "running message is completed")
+
+	backend.mu.Lock()
+	assert.Equal(t, map[string]time.Duration{
+		receipts["a:2"]: 0,
+		receipts["a:3"]: 0,
+		receipts["b:1"]: 0,
+		receipts["b:2"]: 0,
+	}, backend.changed, "messages which are not started are visible again")
+	assert.Equal(t, []string{receipts["a:1"]}, backend.deleted)
+	backend.mu.Unlock()
+	bg := context.Background()
+	for _, id := range []string{"a:2", "a:3", "b:1", "b:2"} {
+		assert.NoError(t, l.Lock(bg, id), "redelivered %s is not duplicated", id)
+	}
+	assert.ErrorIs(t, l.Lock(bg, "a:1"), locker.ErrQueueExists, "completed message stays locked")
+}
+
+func TestWorkerReleasesFailedMessage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		switch q.ID {
+		case "failed":
+			return errors.New("failed")
+		case "retained":
+			return ErrRetainMessage
+		}
+		return nil
+	}
+
+	op := &testQueueOperator{}
+	broker := make(chan Message, 1)
+	startWorker(ctx, testInvoker(testInvokerFn), broker, op)
+
+	for _, id := range []string{"failed", "succeeded", "retained"} {
+		broker <- Message{ID: id}
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	assert.Equal(t, []string{"succeeded"}, op.removed)
+	assert.Equal(t, []string{"failed", "retained"}, op.released)
+}
+
+func TestWorkerRetryPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		if q.ID == "retry-after" {
+			return RetryAfter(3 * time.Second)
+		}
+		return errors.New("failed")
+	}
+
+	op := &testQueueOperator{}
+	broker := make(chan Message, 1)
+	startWorker(ctx, testInvoker(testInvokerFn), broker, op,
+		ConsumerRetryPolicy(RetryPolicy{Base: 10 * time.Second, Max: time.Minute}))
+
+	for _, msg := range []Message{
+		{ID: "first", SystemAttributes: map[string]string{AttributeApproximateReceiveCount: "1"}},
+		{ID: "third", SystemAttributes: map[string]string{AttributeApproximateReceiveCount: "3"}},
+		{ID: "retry-after", SystemAttributes: map[string]string{AttributeApproximateReceiveCount: "3"}},
+	} {
+		broker <- msg
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	assert.Equal(t, []time.Duration{10 * time.Second, 40 * time.Second, 3 * time.Second}, op.timeouts)
+	assert.Equal(t, []string{"first", "third", "retry-after"}, op.released)
+}
+
+func TestWorkerRecoversPanic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		if q.Payload == "broken" {
+			var m map[string]int
+			m["nil map"]++
+		}
+		return nil
+	}
+
+	op := &testQueueOperator{}
+	sink := &testDeadLetterSink{}
+	broker := make(chan Message, 1)
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, op, ConsumerDeadLetter(3, sink))
+
+	for _, msg := range []Message{
+		{ID: "id:1", Payload: "broken", SystemAttributes: map[string]string{AttributeApproximateReceiveCount: "1"}},
+		{ID: "id:2", Payload: "ok"},
+		{ID: "id:3", Payload: "broken", SystemAttributes: map[string]string{AttributeApproximateReceiveCount: "3"}},
+	} {
+		broker <- msg
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Empty(t, w.CurrentWorkings(ctx))
+	assert.Equal(t, int64(2), w.Stats(ctx).GetPanics())
+
+	op.mu.Lock()
+	assert.Equal(t, []string{"id:1"}, op.released)
+	assert.Equal(t, []string{"id:2", "id:3"}, op.removed)
+	op.mu.Unlock()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if assert.Len(t, sink.records, 1) {
+		assert.Equal(t, "id:3", sink.records[0].Message.ID)
+		assert.Contains(t, sink.records[0].LastError, "invoker panics")
+	}
+}
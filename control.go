@@ -0,0 +1,166 @@
+package sqsd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// consumerState holds whether worker receives messages or not.
+type consumerState struct {
+	mu        sync.Mutex
+	state     ConsumerState
+	changedAt time.Time
+}
+
+func (s *consumerState) get() ConsumerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+func (s *consumerState) set(state ConsumerState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != state || s.changedAt.IsZero() {
+		s.state = state
+		s.changedAt = time.Now()
+	}
+}
+
+func (s *consumerState) status() (ConsumerState, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, s.changedAt
+}
+
+// Pause stops fetchers from receiving messages. Running tasks continue until they finish.
+// Messages which are returned by receiving requests in flight after Pause are given back without being invoked.
+func (w *worker) Pause(ctx context.Context) ConsumerState {
+	w.slots.setPaused(true)
+	w.state.set(ConsumerState_CONSUMER_STATE_PAUSED)
+	getLogger().Info("consumer is paused.")
+	return w.state.get()
+}
+
+// Resume restarts receiving messages after Pause or Drain.
+func (w *worker) Resume(ctx context.Context) ConsumerState {
+	w.state.set(ConsumerState_CONSUMER_STATE_RUNNING)
+	w.slots.setPaused(false)
+	getLogger().Info("consumer is resumed.")
+	return w.state.get()
+}
+
+// Drain stops fetchers from receiving messages as well as Pause,
+// and gives back messages which are received but not started yet,
+// such as messages buffered for workers and messages waiting for preceding message in FIFO group.
+// Messages which are received after Drain, by receiving requests in flight, are also given back until Resume.
+// it returns the number of messages which are given back by Drain, and later ones are not counted.
+func (w *worker) Drain(ctx context.Context) (ConsumerState, int) {
+	w.slots.setPaused(true)
+	w.state.set(ConsumerState_CONSUMER_STATE_DRAINING)
+	msgs := append(w.groups.takePending(), w.takeBuffered()...)
+	for _, msg := range msgs {
+		w.giveBack(msg, w.op)
+	}
+	getLogger().Info("consumer is drained.", "released", len(msgs))
+	return w.state.get(), len(msgs)
+}
+
+// takeBuffered receives messages in broker without waiting.
+func (w *worker) takeBuffered() []Message {
+	var msgs []Message
+	for {
+		select {
+		case msg, ok := <-w.broker:
+			if !ok {
+				return msgs
+			}
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+// Status returns state of consumer, and the number of running tasks.
+// If adaptive concurrency is enabled, its latest observation and recent changes are also returned.
+func (w *worker) Status(ctx context.Context) *StatusResponse {
+	state, changedAt := w.state.status()
+	resp := &StatusResponse{
+		State:          state,
+		StateChangedAt: timestamppb.New(changedAt),
+		RunningTasks:   int32(len(w.CurrentWorkings(ctx))),
+		Concurrency:    int32(w.slots.capacity()),
+	}
+	if w.limiter != nil {
+		resp.Adaptive = w.limiter.status()
+	}
+	return resp
+}
+
+// SetConcurrency changes the number of messages which are processed at once, and returns the previous one.
+// Fetchers reserve slots up to the new limit.
+// If adaptive concurrency is enabled, it continues to adjust concurrency from the new limit.
+// When it shrinks, running tasks are not interrupted, and it waits until they finish to fit in the new limit.
+func (w *worker) SetConcurrency(ctx context.Context, n int) (int, error) {
+	if n < 1 {
+		return 0, fmt.Errorf("concurrency must be positive: %d", n)
+	}
+	prev := w.resize(func(int) (int, string) {
+		return n, "changed by SetConcurrency"
+	})
+	getLogger().Info("concurrency is changed.", "concurrency", n, "previous", prev)
+	if n < prev {
+		if err := w.slots.waitUsed(ctx, n); err != nil {
+			return prev, fmt.Errorf("concurrency is changed, but running tasks are not finished: %w", err)
+		}
+	}
+	return prev, nil
+}
+
+// resize changes the number of slots and goroutines to the one which decide returns from current one,
+// and returns the previous one.
+// Changes are serialized, so that limiter records the actual previous one.
+// If decide returns no reason, the change is not recorded.
+func (w *worker) resize(decide func(current int) (int, string)) int {
+	w.resizeMu.Lock()
+	defer w.resizeMu.Unlock()
+	prev := w.slots.capacity()
+	n, reason := decide(prev)
+	w.slots.resize(n)
+	w.resizeRunners(n)
+	if w.limiter != nil && reason != "" {
+		w.limiter.record(prev, n, reason)
+	}
+	return prev
+}
+
+// resizeRunners starts or stops goroutines which process messages.
+// stopped goroutine exits after its running task finishes.
+// it must be called with resizeMu held, except for startWorker.
+func (w *worker) resizeRunners(n int) {
+	for len(w.runners) < n {
+		stop := make(chan struct{})
+		w.runners = append(w.runners, stop)
+		w.startRun(stop)
+	}
+	for len(w.runners) > n {
+		last := len(w.runners) - 1
+		close(w.runners[last])
+		w.runners = w.runners[:last]
+	}
+}
+
+// giveBack makes message visible in queue immediately without processing it, and frees its slot.
+func (w *worker) giveBack(msg Message, op queueOperator) {
+	ctx := context.Background()
+	defer w.slots.release(msg.QueueURL, 1)
+	if err := op.changeVisibility(ctx, msg, 0); err != nil {
+		getLogger().Warn("failed to give back message", "message_id", msg.ID, "error", err)
+	}
+	w.release(ctx, msg, op)
+}
@@ -0,0 +1,270 @@
+package sqsd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWorkerPause(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	op := &testQueueOperator{}
+	broker := make(chan Message, 2)
+	w := startWorker(ctx, testInvoker(func(context.Context, Message) error { return nil }), broker, op)
+	monitor := NewMonitoringService(w)
+	acq := w.slots.forTenant("queue")
+
+	status, err := monitor.Status(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ConsumerState_CONSUMER_STATE_RUNNING, status.GetState())
+	startedAt := status.GetStateChangedAt().AsTime()
+
+	resp, err := monitor.Pause(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ConsumerState_CONSUMER_STATE_PAUSED, resp.GetState())
+
+	// fetcher can not reserve slots while paused.
+	acquireCtx, acquireCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer acquireCancel()
+	_, err = acq.acquire(acquireCtx, 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// received messages are still processed.
+	broker <- Message{ID: "id:1"}
+	time.Sleep(50 * time.Millisecond)
+	op.mu.Lock()
+	assert.Equal(t, []string{"id:1"}, op.removed)
+	op.mu.Unlock()
+
+	acquired := make(chan int, 1)
+	go func() {
+		n, _ := acq.acquire(ctx, 1)
+		acquired <- n
+	}()
+	time.Sleep(20 * time.Millisecond)
+	_, err = monitor.Resume(ctx, nil)
+	require.NoError(t, err)
+	select {
+	case n := <-acquired:
+		assert.Equal(t, 1, n)
+	case <-time.After(time.Second):
+		t.Fatal("slot is not reserved after resume")
+	}
+
+	status, err = monitor.Status(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ConsumerState_CONSUMER_STATE_RUNNING, status.GetState())
+	assert.True(t, status.GetStateChangedAt().AsTime().After(startedAt))
+}
+
+func TestWorkerDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	nextCh := make(chan struct{})
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		<-nextCh
+		return nil
+	}
+
+	op := &testQueueOperator{fifo: true}
+	broker := make(chan Message, 3)
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, op)
+	monitor := NewMonitoringService(w)
+
+	group := map[string]string{AttributeMessageGroupID: "group-1"}
+	for _, id := range []string{"id:1", "id:2", "id:3"} {
+		broker <- Message{ID: id, SystemAttributes: group}
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err := monitor.Drain(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ConsumerState_CONSUMER_STATE_DRAINING, resp.GetState())
+	assert.Equal(t, int32(2), resp.GetReleased())
+
+	// messages received by requests in flight are also given back.
+	broker <- Message{ID: "id:4"}
+	time.Sleep(50 * time.Millisecond)
+
+	status, err := monitor.Status(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ConsumerState_CONSUMER_STATE_DRAINING, status.GetState())
+	assert.Equal(t, int32(1), status.GetRunningTasks())
+
+	// running task finishes.
+	close(nextCh)
+	time.Sleep(50 * time.Millisecond)
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	assert.Equal(t, []string{"id:1"}, op.removed)
+	assert.ElementsMatch(t, []string{"id:2", "id:3", "id:4"}, op.released)
+	assert.Equal(t, []time.Duration{0, 0, 0}, op.timeouts)
+}
+
+// gatedQueueBackend holds receiving requests until gate is closed.
+type gatedQueueBackend struct {
+	*testQueueBackend
+	gate chan struct{}
+}
+
+func (b gatedQueueBackend) Receive(ctx context.Context, in ReceiveInput) ([]Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-b.gate:
+	}
+	return b.testQueueBackend.Receive(ctx, in)
+}
+
+func TestWorkerPauseReceivingInFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	queueURL := "http://localhost:9324/queue/pause"
+	backend := gatedQueueBackend{testQueueBackend: newTestQueueBackend(), gate: make(chan struct{})}
+	backend.push(Message{ID: "id:1"})
+	var invoked []string
+	var mu sync.Mutex
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		invoked = append(invoked, q.ID)
+		return nil
+	}
+
+	op := &testQueueOperator{}
+	broker := make(chan Message, 2)
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, op)
+	monitor := NewMonitoringService(w)
+	g := NewGateway(backend, queueURL)
+	go g.start(ctx, broker, w.slots.forTenant(queueURL))
+	// fetcher is receiving messages.
+	time.Sleep(20 * time.Millisecond)
+
+	resp, err := monitor.Pause(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ConsumerState_CONSUMER_STATE_PAUSED, resp.GetState())
+
+	close(backend.gate)
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	assert.Empty(t, invoked, "message received after Pause is not invoked")
+	mu.Unlock()
+	backend.mu.Lock()
+	assert.Equal(t, map[string]time.Duration{"receipt-1": 0}, backend.changed)
+	backend.mu.Unlock()
+}
+
+func TestWorkerDrainBufferedMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	nextCh := make(chan struct{})
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		<-nextCh
+		return nil
+	}
+
+	op := &testQueueOperator{}
+	broker := make(chan Message, 1)
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, op)
+	monitor := NewMonitoringService(w)
+
+	// id:1 is running, id:2 is held by dispatcher, and id:3 is buffered in broker.
+	for _, id := range []string{"id:1", "id:2", "id:3"} {
+		broker <- Message{ID: id}
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err := monitor.Drain(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), resp.GetReleased())
+
+	close(nextCh)
+	time.Sleep(50 * time.Millisecond)
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	assert.Equal(t, []string{"id:1"}, op.removed)
+	assert.Equal(t, []string{"id:3", "id:2"}, op.released)
+	assert.Equal(t, []time.Duration{0, 0}, op.timeouts)
+}
+
+func TestWorkerSetConcurrency(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	nextCh := make(chan struct{})
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		<-nextCh
+		return nil
+	}
+
+	broker := make(chan Message, 1)
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, &testQueueOperator{})
+	monitor := NewMonitoringService(w)
+	acq := w.slots.forTenant("")
+
+	_, err := monitor.SetConcurrency(ctx, &SetConcurrencyRequest{Concurrency: 0})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err := monitor.SetConcurrency(ctx, &SetConcurrencyRequest{Concurrency: 3})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), resp.GetConcurrency())
+	assert.Equal(t, int32(1), resp.GetPrevious())
+
+	// fetcher reserves slots up to the new limit, and messages are processed in parallel.
+	n, err := acq.acquire(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	for i := 1; i <= 3; i++ {
+		broker <- Message{ID: fmt.Sprintf("id:%d", i)}
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, w.CurrentWorkings(ctx), 3)
+
+	// shrinking waits until running tasks fit in the new limit.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		prev, err := w.SetConcurrency(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, prev)
+	}()
+	nextCh <- struct{}{}
+	select {
+	case <-done:
+		t.Fatal("SetConcurrency returns before tasks finish")
+	case <-time.After(50 * time.Millisecond):
+	}
+	nextCh <- struct{}{}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("SetConcurrency does not return after tasks finish")
+	}
+	assert.Len(t, w.CurrentWorkings(ctx), 1)
+
+	status, err := monitor.Status(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), status.GetConcurrency())
+	w.resizeMu.Lock()
+	assert.Len(t, w.runners, 1)
+	w.resizeMu.Unlock()
+
+	close(nextCh)
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, w.CurrentWorkings(ctx))
+
+	_, err = NewSystem().SetConcurrency(ctx, 2)
+	assert.EqualError(t, err, "system is not running")
+}
@@ -0,0 +1,174 @@
+package sqsd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule represents parsed cron expression such as "*/5 * * * *".
+// each field is held as bit set.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// restricted flags show that day-of-month or day-of-week does not start with "*".
+	// in standard cron, if both are restricted, the day matches either one.
+	domRestricted, dowRestricted bool
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{min: 0, max: 59}
+	hourField   = cronField{min: 0, max: 23}
+	domField    = cronField{min: 1, max: 31}
+	monthField  = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is also accepted as sunday.
+	dowField = cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCronSchedule parses standard 5 fields cron expression.
+func parseCronSchedule(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields: %q", expr)
+	}
+	var s cronSchedule
+	var err error
+	for _, f := range []struct {
+		dst   *uint64
+		field cronField
+		expr  string
+	}{
+		{&s.minute, minuteField, fields[0]},
+		{&s.hour, hourField, fields[1]},
+		{&s.dom, domField, fields[2]},
+		{&s.month, monthField, fields[3]},
+		{&s.dow, dowField, fields[4]},
+	} {
+		if *f.dst, err = f.field.parse(f.expr); err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1 << 0
+	}
+	s.domRestricted = !strings.HasPrefix(fields[2], "*")
+	s.dowRestricted = !strings.HasPrefix(fields[4], "*")
+	return &s, nil
+}
+
+func (f cronField) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rng, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepExpr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step: %q", part)
+			}
+			step = n
+		}
+		start, end := f.min, f.max
+		switch lo, hi, isRange := strings.Cut(rng, "-"); {
+		case rng == "*":
+		case isRange:
+			var err error
+			if start, err = f.value(lo); err != nil {
+				return 0, err
+			}
+			if end, err = f.value(hi); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if start, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			// "a/n" means from a to max by n.
+			if !hasStep {
+				end = start
+			}
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid range: %q", part)
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << i
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(expr string) (int, error) {
+	if n, ok := f.names[strings.ToLower(expr)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value: %q", expr)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("value out of range [%d-%d]: %d", f.min, f.max, n)
+	}
+	return n, nil
+}
+
+// next returns the earliest time which matches schedule after t.
+// if nothing matches within 5 years, returns zero time.
+func (s *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	domMatched := s.dom&(1<<uint(t.Day())) != 0
+	dowMatched := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatched || dowMatched
+	}
+	return domMatched && dowMatched
+}
@@ -0,0 +1,58 @@
+package sqsd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// 2024-03-15 is Friday.
+	base := time.Date(2024, 3, 15, 10, 7, 30, 0, time.UTC)
+	for _, tt := range []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 3, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 3, 15, 10, 15, 0, 0, time.UTC)},
+		{"5/30 * * * *", time.Date(2024, 3, 15, 10, 35, 0, 0, time.UTC)},
+		{"0 */12 * * *", time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 3, 15, 13, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2024, 3, 16, 2, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * mon", time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// day-of-month or day-of-week
+		{"0 0 20 * 6", time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := parseCronSchedule(tt.expr)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, s.next(base))
+			}
+		})
+	}
+}
+
+func TestCronScheduleInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+	} {
+		_, err := parseCronSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,197 @@
+package sqsd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// DeadLetterRecord is the message which worker gives up processing, with metadata of its failure.
+type DeadLetterRecord struct {
+	Message    Message
+	LastError  string
+	StatusCode int
+	Attempts   int
+	FailedAt   time.Time
+}
+
+func newDeadLetterRecord(msg Message, err error) DeadLetterRecord {
+	rec := DeadLetterRecord{
+		Message:  msg,
+		Attempts: msg.ReceiveCount(),
+		FailedAt: time.Now().UTC(),
+	}
+	if err != nil {
+		rec.LastError = err.Error()
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		rec.StatusCode = statusErr.StatusCode
+	}
+	return rec
+}
+
+// DeadLetterSink stores messages which worker gives up processing.
+type DeadLetterSink interface {
+	Put(ctx context.Context, rec DeadLetterRecord) error
+}
+
+// ConsumerDeadLetter makes worker forward failed message to sink and remove it from queue,
+// when ApproximateReceiveCount of the message reaches to maxAttempts,
+// or when invoker returns ErrDeadLetter.
+// if maxAttempts is 0, only messages of ErrDeadLetter are forwarded.
+// if maxAttempts is positive, System receives ApproximateReceiveCount even if FetcherSystemAttributes does not contain it.
+// if forwarding is failed, message is retried as usual.
+func ConsumerDeadLetter(maxAttempts int, sink DeadLetterSink) ConsumerParameter {
+	return func(p *consumerParams) {
+		p.maxAttempts = maxAttempts
+		p.deadLetterSink = sink
+	}
+}
+
+// shouldDeadLetter reports whether failed message is forwarded to dead-letter sink.
+func (c consumerParams) shouldDeadLetter(msg Message, err error) bool {
+	if c.deadLetterSink == nil {
+		return false
+	}
+	if errors.Is(err, ErrDeadLetter) {
+		return true
+	}
+	return c.maxAttempts > 0 && msg.ReceiveCount() >= c.maxAttempts
+}
+
+// deadLetterAttribute is the name of message attribute which holds failure metadata in dead-letter queue.
+const deadLetterAttribute = "SqsdDeadLetter"
+
+// QueueDeadLetterSink sends dead-letter messages to queue.
+// Original message attributes are kept, and failure metadata is added as JSON in "SqsdDeadLetter" attribute.
+// Because SQS accepts 10 message attributes at most, message which already has 10 attributes can not be sent.
+type QueueDeadLetterSink struct {
+	backend  QueueBackend
+	queueURL string
+}
+
+// NewQueueDeadLetterSink returns QueueDeadLetterSink object.
+func NewQueueDeadLetterSink(backend QueueBackend, queueURL string) *QueueDeadLetterSink {
+	return &QueueDeadLetterSink{
+		backend:  backend,
+		queueURL: queueURL,
+	}
+}
+
+type deadLetterMetadata struct {
+	SourceQueue string    `json:"source_queue"`
+	MessageID   string    `json:"message_id"`
+	LastError   string    `json:"last_error"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Attempts    int       `json:"attempts"`
+	FailedAt    time.Time `json:"failed_at"`
+}
+
+func (rec DeadLetterRecord) metadata() deadLetterMetadata {
+	return deadLetterMetadata{
+		SourceQueue: rec.Message.QueueURL,
+		MessageID:   rec.Message.ID,
+		LastError:   rec.LastError,
+		StatusCode:  rec.StatusCode,
+		Attempts:    rec.Attempts,
+		FailedAt:    rec.FailedAt,
+	}
+}
+
+// Put sends message to dead-letter queue.
+func (s *QueueDeadLetterSink) Put(ctx context.Context, rec DeadLetterRecord) error {
+	meta, err := json.Marshal(rec.metadata())
+	if err != nil {
+		return err
+	}
+	attrs := make(map[string]MessageAttribute, len(rec.Message.Attributes)+1)
+	for name, attr := range rec.Message.Attributes {
+		attrs[name] = attr
+	}
+	attrs[deadLetterAttribute] = MessageAttribute{
+		DataType:    "String",
+		StringValue: string(meta),
+	}
+	in := SendInput{
+		QueueURL:   s.queueURL,
+		Body:       rec.Message.Payload,
+		Attributes: attrs,
+	}
+	if isFIFOQueue(s.queueURL) {
+		in.MessageGroupID = rec.Message.MessageGroupID()
+		if in.MessageGroupID == "" {
+			in.MessageGroupID = "dead-letter"
+		}
+		in.DeduplicationID = rec.Message.ID
+	}
+	_, err = s.backend.Send(ctx, in)
+	return err
+}
+
+// FileDeadLetterSink appends dead-letter messages to local file in JSON lines format.
+type FileDeadLetterSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileDeadLetterSink opens file to append dead-letter messages.
+func NewFileDeadLetterSink(path string) (*FileDeadLetterSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileDeadLetterSink{file: f}, nil
+}
+
+type deadLetterLine struct {
+	ID                string                    `json:"id"`
+	Body              string                    `json:"body"`
+	SystemAttributes  map[string]string         `json:"system_attributes,omitempty"`
+	MessageAttributes map[string]deadLetterAttr `json:"message_attributes,omitempty"`
+	DeadLetter        deadLetterMetadata        `json:"dead_letter"`
+}
+
+type deadLetterAttr struct {
+	DataType    string `json:"data_type"`
+	StringValue string `json:"string_value,omitempty"`
+	BinaryValue []byte `json:"binary_value,omitempty"`
+}
+
+// Put writes message as a line.
+func (s *FileDeadLetterSink) Put(ctx context.Context, rec DeadLetterRecord) error {
+	line := deadLetterLine{
+		ID:               rec.Message.ID,
+		Body:             rec.Message.Payload,
+		SystemAttributes: rec.Message.SystemAttributes,
+		DeadLetter:       rec.metadata(),
+	}
+	if len(rec.Message.Attributes) > 0 {
+		line.MessageAttributes = make(map[string]deadLetterAttr, len(rec.Message.Attributes))
+		for name, attr := range rec.Message.Attributes {
+			line.MessageAttributes[name] = deadLetterAttr{
+				DataType:    attr.DataType,
+				StringValue: attr.StringValue,
+				BinaryValue: attr.BinaryValue,
+			}
+		}
+	}
+	b, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(b, '\n'))
+	return err
+}
+
+// Close closes file.
+func (s *FileDeadLetterSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,175 @@
+package sqsd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testDeadLetterSink struct {
+	mu      sync.Mutex
+	records []DeadLetterRecord
+	err     error
+}
+
+func (s *testDeadLetterSink) Put(_ context.Context, rec DeadLetterRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func TestWorkerDeadLetter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		if q.ID == "dead" {
+			return DeadLetter(&StatusError{StatusCode: http.StatusNotFound})
+		}
+		return &StatusError{StatusCode: http.StatusInternalServerError}
+	}
+
+	op := &testQueueOperator{}
+	sink := &testDeadLetterSink{}
+	broker := make(chan Message, 1)
+	startWorker(ctx, testInvoker(testInvokerFn), broker, op, ConsumerDeadLetter(3, sink))
+
+	for _, msg := range []Message{
+		{ID: "id:1", SystemAttributes: map[string]string{AttributeApproximateReceiveCount: "2"}},
+		{ID: "id:2", SystemAttributes: map[string]string{AttributeApproximateReceiveCount: "3"}},
+		{ID: "dead", SystemAttributes: map[string]string{AttributeApproximateReceiveCount: "1"}},
+	} {
+		broker <- msg
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	op.mu.Lock()
+	assert.Equal(t, []string{"id:1"}, op.released)
+	assert.Equal(t, []string{"id:2", "dead"}, op.removed)
+	op.mu.Unlock()
+
+	sink.mu.Lock()
+	if assert.Len(t, sink.records, 2) {
+		rec := sink.records[0]
+		assert.Equal(t, "id:2", rec.Message.ID)
+		assert.Equal(t, 3, rec.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, rec.StatusCode)
+		assert.Equal(t, "failure response: 500", rec.LastError)
+		assert.False(t, rec.FailedAt.IsZero())
+
+		rec = sink.records[1]
+		assert.Equal(t, "dead", rec.Message.ID)
+		assert.Equal(t, http.StatusNotFound, rec.StatusCode)
+	}
+	sink.err = errors.New("unavailable")
+	sink.mu.Unlock()
+
+	// message is retried as usual if forwarding is failed.
+	broker <- Message{ID: "id:3", SystemAttributes: map[string]string{AttributeApproximateReceiveCount: "5"}}
+	time.Sleep(50 * time.Millisecond)
+	op.mu.Lock()
+	assert.Equal(t, []string{"id:1", "id:3"}, op.released)
+	assert.Equal(t, []string{"id:2", "dead"}, op.removed)
+	op.mu.Unlock()
+}
+
+func TestFileDeadLetterSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	sink, err := NewFileDeadLetterSink(path)
+	assert.NoError(t, err)
+
+	failedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, id := range []string{"id:1", "id:2"} {
+		assert.NoError(t, sink.Put(context.Background(), DeadLetterRecord{
+			Message: Message{
+				ID:               id,
+				Payload:          `{"foo":"bar"}`,
+				QueueURL:         "http://localhost:9324/queue/test",
+				SystemAttributes: map[string]string{AttributeApproximateReceiveCount: "3"},
+				Attributes: map[string]MessageAttribute{
+					"Kind": {DataType: "String", StringValue: "user"},
+				},
+			},
+			LastError:  "failure response: 500",
+			StatusCode: 500,
+			Attempts:   3,
+			FailedAt:   failedAt,
+		}))
+	}
+	assert.NoError(t, sink.Close())
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+	var lines []deadLetterLine
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line deadLetterLine
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, deadLetterLine{
+			ID:               "id:1",
+			Body:             `{"foo":"bar"}`,
+			SystemAttributes: map[string]string{AttributeApproximateReceiveCount: "3"},
+			MessageAttributes: map[string]deadLetterAttr{
+				"Kind": {DataType: "String", StringValue: "user"},
+			},
+			DeadLetter: deadLetterMetadata{
+				SourceQueue: "http://localhost:9324/queue/test",
+				MessageID:   "id:1",
+				LastError:   "failure response: 500",
+				StatusCode:  500,
+				Attempts:    3,
+				FailedAt:    failedAt,
+			},
+		}, lines[0])
+		assert.Equal(t, "id:2", lines[1].ID)
+	}
+}
+
+func TestQueueDeadLetterSink(t *testing.T) {
+	backend := newTestQueueBackend()
+	sink := NewQueueDeadLetterSink(backend, "http://localhost:9324/queue/dlq.fifo")
+	err := sink.Put(context.Background(), DeadLetterRecord{
+		Message: Message{
+			ID:       "id:1",
+			Payload:  "hello",
+			QueueURL: "http://localhost:9324/queue/test.fifo",
+			SystemAttributes: map[string]string{
+				AttributeMessageGroupID: "group-1",
+			},
+			Attributes: map[string]MessageAttribute{
+				"Kind": {DataType: "String", StringValue: "user"},
+			},
+		},
+		LastError: "failed",
+		Attempts:  5,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, backend.sent, 1) {
+		in := backend.sent[0]
+		assert.Equal(t, "http://localhost:9324/queue/dlq.fifo", in.QueueURL)
+		assert.Equal(t, "hello", in.Body)
+		assert.Equal(t, "group-1", in.MessageGroupID)
+		assert.Equal(t, "id:1", in.DeduplicationID)
+		assert.Equal(t, "user", in.Attributes["Kind"].StringValue)
+		var meta deadLetterMetadata
+		assert.NoError(t, json.Unmarshal([]byte(in.Attributes[deadLetterAttribute].StringValue), &meta))
+		assert.Equal(t, "http://localhost:9324/queue/test.fifo", meta.SourceQueue)
+		assert.Equal(t, "failed", meta.LastError)
+		assert.Equal(t, 5, meta.Attempts)
+	}
+}
@@ -0,0 +1,168 @@
+package sqsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxDeleteBatchSize is the upper limit of entries in DeleteMessageBatch.
+const maxDeleteBatchSize = 10
+
+type deleteEntry struct {
+	msg      Message
+	attempts int
+	done     chan error
+}
+
+// deleteBatcher collects messages from workers and deletes them at once.
+// Batch is flushed when it has 10 entries or linger duration passes after first entry is added.
+type deleteBatcher struct {
+	backend    QueueBackend
+	queueURL   string
+	linger     time.Duration
+	maxRetries int
+
+	mu       sync.Mutex
+	pending  []*deleteEntry
+	timer    *time.Timer
+	draining bool
+	// flushing counts running and scheduled flushes, and idle is signaled when it becomes 0.
+	flushing int
+	idle     *sync.Cond
+	inflight atomic.Int64
+}
+
+func newDeleteBatcher(backend QueueBackend, queueURL string, linger time.Duration, maxRetries int) *deleteBatcher {
+	b := &deleteBatcher{
+		backend:    backend,
+		queueURL:   queueURL,
+		linger:     linger,
+		maxRetries: maxRetries,
+	}
+	b.idle = sync.NewCond(&b.mu)
+	return b
+}
+
+// delete adds message to batch, and waits until it is deleted or given up.
+func (b *deleteBatcher) delete(msg Message) error {
+	b.inflight.Add(1)
+	defer b.inflight.Add(-1)
+	e := &deleteEntry{
+		msg:  msg,
+		done: make(chan error, 1),
+	}
+	b.add(e)
+	return <-e.done
+}
+
+func (b *deleteBatcher) add(e *deleteEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, e)
+	if len(b.pending) >= maxDeleteBatchSize || b.draining || b.linger <= 0 {
+		b.flushLocked()
+		return
+	}
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.linger, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.flushLocked()
+		})
+	}
+}
+
+func (b *deleteBatcher) flushLocked() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	for len(b.pending) > 0 {
+		n := len(b.pending)
+		if n > maxDeleteBatchSize {
+			n = maxDeleteBatchSize
+		}
+		batch := b.pending[:n:n]
+		b.pending = b.pending[n:]
+		b.flushing++
+		go func() {
+			defer b.flushDone()
+			b.flush(batch)
+		}()
+	}
+	b.pending = nil
+}
+
+func (b *deleteBatcher) flush(batch []*deleteEntry) {
+	receipts := make([]string, 0, len(batch))
+	for _, e := range batch {
+		receipts = append(receipts, e.msg.Receipt)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	errs, err := b.backend.DeleteMessages(ctx, b.queueURL, receipts)
+	cancel()
+	if err != nil {
+		for _, e := range batch {
+			b.retry(e, err)
+		}
+		return
+	}
+	logger := getLogger()
+	for i, e := range batch {
+		if i >= len(errs) || errs[i] == nil {
+			logger.Debug("succeeded to remove message", "message_id", e.msg.ID)
+			e.done <- nil
+			continue
+		}
+		err := fmt.Errorf("failed to delete message: %w", errs[i])
+		var entryErr *BatchEntryError
+		if errors.As(err, &entryErr) && entryErr.SenderFault {
+			// request itself is wrong, such as expired receipt handle, so retrying is meaningless.
+			e.done <- err
+			continue
+		}
+		b.retry(e, err)
+	}
+}
+
+// retry adds entry to next batch after a second, until attempts reach to maxRetries.
+func (b *deleteBatcher) retry(e *deleteEntry, err error) {
+	e.attempts++
+	if e.attempts >= b.maxRetries {
+		e.done <- err
+		return
+	}
+	getLogger().Debug("retry to remove message", "message_id", e.msg.ID, "attempts", e.attempts, "error", err)
+	b.mu.Lock()
+	b.flushing++
+	b.mu.Unlock()
+	time.AfterFunc(time.Second, func() {
+		defer b.flushDone()
+		b.add(e)
+	})
+}
+
+func (b *deleteBatcher) flushDone() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushing--
+	if b.flushing == 0 {
+		b.idle.Broadcast()
+	}
+}
+
+// drain flushes pending entries immediately, and makes later entries flushed without lingering.
+// it waits until all flushing batches finish.
+func (b *deleteBatcher) drain() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.draining = true
+	b.flushLocked()
+	for b.flushing > 0 {
+		b.idle.Wait()
+	}
+}
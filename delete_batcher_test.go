@@ -0,0 +1,145 @@
+package sqsd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/assert"
+)
+
+type deleteBatchTestServer struct {
+	mu      sync.Mutex
+	batches [][]string
+	// failures holds receipt handles and count of failure before success.
+	failures map[string]int
+}
+
+func (s *deleteBatchTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Amz-Target") != "AmazonSQS.DeleteMessageBatch" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var in struct {
+		Entries []struct {
+			Id            string
+			ReceiptHandle string
+		}
+	}
+	_ = json.NewDecoder(r.Body).Decode(&in)
+
+	type entry struct {
+		Id          string
+		Code        string `json:",omitempty"`
+		SenderFault bool   `json:",omitempty"`
+	}
+	out := struct {
+		Successful []entry
+		Failed     []entry
+	}{
+		Successful: []entry{},
+		Failed:     []entry{},
+	}
+	s.mu.Lock()
+	receipts := make([]string, 0, len(in.Entries))
+	for _, e := range in.Entries {
+		receipts = append(receipts, e.ReceiptHandle)
+		switch {
+		case strings.HasPrefix(e.ReceiptHandle, "invalid"):
+			out.Failed = append(out.Failed, entry{Id: e.Id, Code: "ReceiptHandleIsInvalid", SenderFault: true})
+		case s.failures[e.ReceiptHandle] > 0:
+			s.failures[e.ReceiptHandle]--
+			out.Failed = append(out.Failed, entry{Id: e.Id, Code: "InternalError"})
+		default:
+			out.Successful = append(out.Successful, entry{Id: e.Id})
+		}
+	}
+	s.batches = append(s.batches, receipts)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+func TestDeleteBatcher(t *testing.T) {
+	ts := &deleteBatchTestServer{
+		failures: map[string]int{"retry-1": 1},
+	}
+	srv := httptest.NewServer(ts)
+	defer srv.Close()
+
+	queue := sqs.NewFromConfig(awsConf, func(o *sqs.Options) {
+		o.BaseEndpoint = &srv.URL
+	})
+	b := newDeleteBatcher(NewSQSBackend(queue), srv.URL+"/queue/test", 100*time.Millisecond, 3)
+
+	results := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	receipts := []string{"invalid-1", "retry-1"}
+	for i := 0; i < 11; i++ {
+		receipts = append(receipts, fmt.Sprintf("receipt-%d", i))
+	}
+	for _, receipt := range receipts {
+		wg.Add(1)
+		go func(receipt string) {
+			defer wg.Done()
+			err := b.delete(Message{ID: receipt, Receipt: receipt})
+			mu.Lock()
+			results[receipt] = err
+			mu.Unlock()
+		}(receipt)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(0), b.inflight.Load())
+	for _, receipt := range receipts {
+		if receipt == "invalid-1" {
+			assert.Error(t, results[receipt])
+		} else {
+			assert.NoError(t, results[receipt], receipt)
+		}
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, batch := range ts.batches {
+		assert.LessOrEqual(t, len(batch), maxDeleteBatchSize)
+	}
+	// 13 receipts are sent by 2 batches, and failed one is retried by another batch.
+	assert.Len(t, ts.batches, 3)
+	assert.Equal(t, []string{"retry-1"}, ts.batches[2])
+}
+
+func TestDeleteBatcherDrain(t *testing.T) {
+	ts := &deleteBatchTestServer{}
+	srv := httptest.NewServer(ts)
+	defer srv.Close()
+
+	queue := sqs.NewFromConfig(awsConf, func(o *sqs.Options) {
+		o.BaseEndpoint = &srv.URL
+	})
+	b := newDeleteBatcher(NewSQSBackend(queue), srv.URL+"/queue/test", time.Hour, 3)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- b.delete(Message{ID: "1", Receipt: "receipt-1"})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(1), b.inflight.Load())
+
+	b.drain()
+	assert.NoError(t, <-errCh)
+
+	// after draining, message is deleted without lingering.
+	assert.NoError(t, b.delete(Message{ID: "2", Receipt: "receipt-2"}))
+	ts.mu.Lock()
+	assert.Equal(t, [][]string{{"receipt-1"}, {"receipt-2"}}, ts.batches)
+	ts.mu.Unlock()
+}
@@ -0,0 +1,614 @@
+package devqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sqsd "github.com/taiyoh/sqsd/v2"
+	memorybackend "github.com/taiyoh/sqsd/v2/backend/memory"
+)
+
+type actionFunc func(s *Server, ctx context.Context, req request) (any, error)
+
+var actions = map[string]actionFunc{
+	"CreateQueue":             decoded((*Server).createQueue),
+	"DeleteQueue":             decoded((*Server).deleteQueue),
+	"GetQueueUrl":             decoded((*Server).getQueueURL),
+	"ListQueues":              decoded((*Server).listQueues),
+	"GetQueueAttributes":      decoded((*Server).getQueueAttributes),
+	"SendMessage":             decoded((*Server).sendMessage),
+	"SendMessageBatch":        decoded((*Server).sendMessageBatch),
+	"ReceiveMessage":          decoded((*Server).receiveMessage),
+	"DeleteMessage":           decoded((*Server).deleteMessage),
+	"DeleteMessageBatch":      decoded((*Server).deleteMessageBatch),
+	"ChangeMessageVisibility": decoded((*Server).changeMessageVisibility),
+}
+
+// decoded makes actionFunc which decodes parameters into T before calling f.
+func decoded[T any](f func(s *Server, ctx context.Context, req request, in T) (any, error)) actionFunc {
+	return func(s *Server, ctx context.Context, req request) (any, error) {
+		var in T
+		if err := req.decode(&in); err != nil {
+			return nil, invalidParameter("failed to decode parameters: %v", err)
+		}
+		return f(s, ctx, req, in)
+	}
+}
+
+// number is an integer parameter, which is given as either JSON number or string.
+type number int
+
+func (n *number) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*n = number(v)
+	return nil
+}
+
+func checkRange(name string, v number, min, max int) error {
+	if int(v) < min || int(v) > max {
+		return invalidParameter("Value %d for parameter %s is invalid. Reason: must be between %d and %d.", v, name, min, max)
+	}
+	return nil
+}
+
+var queueNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,80}$`)
+
+type createQueueInput struct {
+	QueueName  string
+	Attributes map[string]string
+}
+
+type queueURLOutput struct {
+	QueueUrl string
+}
+
+func (s *Server) createQueue(ctx context.Context, req request, in createQueueInput) (any, error) {
+	if in.QueueName == "" {
+		return nil, missingParameter("QueueName")
+	}
+	if !queueNamePattern.MatchString(strings.TrimSuffix(in.QueueName, ".fifo")) {
+		return nil, invalidParameter("Can only include alphanumeric characters, hyphens, or underscores. 1 to 80 in length")
+	}
+	conf, err := queueConfig(in.QueueName, in.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.backend.CreateQueue(in.QueueName, conf); err != nil && !errors.Is(err, memorybackend.ErrQueueExists) {
+		return nil, err
+	}
+	return queueURLOutput{QueueUrl: req.queueURL(in.QueueName)}, nil
+}
+
+// ignoredAttributes are accepted by CreateQueue, but they do not affect in-memory queue.
+var ignoredAttributes = map[string]bool{
+	"MaximumMessageSize":            true,
+	"MessageRetentionPeriod":        true,
+	"ReceiveMessageWaitTimeSeconds": true,
+	"ContentBasedDeduplication":     true,
+	"DeduplicationScope":            true,
+	"FifoThroughputLimit":           true,
+	"KmsMasterKeyId":                true,
+	"KmsDataKeyReusePeriodSeconds":  true,
+	"SqsManagedSseEnabled":          true,
+	"Policy":                        true,
+	"RedriveAllowPolicy":            true,
+}
+
+type redrivePolicy struct {
+	DeadLetterTargetArn string `json:"deadLetterTargetArn"`
+	MaxReceiveCount     number `json:"maxReceiveCount"`
+}
+
+func queueConfig(name string, attrs map[string]string) (memorybackend.Config, error) {
+	var conf memorybackend.Config
+	for key, val := range attrs {
+		switch key {
+		case "VisibilityTimeout", "DelaySeconds":
+			v, err := strconv.Atoi(val)
+			if err != nil {
+				return conf, invalidParameter("Invalid value for the parameter %s.", key)
+			}
+			if key == "VisibilityTimeout" {
+				err = checkRange(key, number(v), 0, 43200)
+				conf.VisibilityTimeout = time.Duration(v) * time.Second
+			} else {
+				err = checkRange(key, number(v), 0, 900)
+				conf.Delay = time.Duration(v) * time.Second
+			}
+			if err != nil {
+				return conf, err
+			}
+		case "RedrivePolicy":
+			var policy redrivePolicy
+			if err := json.Unmarshal([]byte(val), &policy); err != nil || policy.DeadLetterTargetArn == "" || policy.MaxReceiveCount < 1 {
+				return conf, invalidParameter("Invalid value for the parameter RedrivePolicy.")
+			}
+			conf.DeadLetterQueue = policy.DeadLetterTargetArn[strings.LastIndex(policy.DeadLetterTargetArn, ":")+1:]
+			conf.MaxReceiveCount = int(policy.MaxReceiveCount)
+		case "FifoQueue":
+			if fifo := val == "true"; fifo != strings.HasSuffix(name, ".fifo") {
+				return conf, invalidParameter("The name of a FIFO queue can only include alphanumeric characters, hyphens, or underscores, must end with .fifo suffix.")
+			}
+		default:
+			if !ignoredAttributes[key] {
+				return conf, invalidParameter("Unknown Attribute %s.", key)
+			}
+		}
+	}
+	return conf, nil
+}
+
+type queueURLInput struct {
+	QueueUrl string
+}
+
+func (s *Server) deleteQueue(ctx context.Context, req request, in queueURLInput) (any, error) {
+	name, err := s.queueName(req, in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.backend.DeleteQueue(name); err != nil {
+		return nil, errQueueDoesNotExist
+	}
+	return struct{}{}, nil
+}
+
+type getQueueURLInput struct {
+	QueueName string
+}
+
+func (s *Server) getQueueURL(ctx context.Context, req request, in getQueueURLInput) (any, error) {
+	if in.QueueName == "" {
+		return nil, missingParameter("QueueName")
+	}
+	if _, err := s.backend.QueueConfig(in.QueueName); err != nil {
+		return nil, errQueueDoesNotExist
+	}
+	return queueURLOutput{QueueUrl: req.queueURL(in.QueueName)}, nil
+}
+
+type listQueuesInput struct {
+	QueueNamePrefix string
+}
+
+type listQueuesOutput struct {
+	QueueUrls []string `json:",omitempty"`
+}
+
+func (s *Server) listQueues(ctx context.Context, req request, in listQueuesInput) (any, error) {
+	var out listQueuesOutput
+	for _, name := range s.backend.Queues() {
+		if strings.HasPrefix(name, in.QueueNamePrefix) {
+			out.QueueUrls = append(out.QueueUrls, req.queueURL(name))
+		}
+	}
+	return out, nil
+}
+
+type getQueueAttributesInput struct {
+	QueueUrl       string
+	AttributeNames []string
+}
+
+type getQueueAttributesOutput struct {
+	Attributes map[string]string `json:",omitempty"`
+}
+
+func (s *Server) getQueueAttributes(ctx context.Context, req request, in getQueueAttributesInput) (any, error) {
+	name, err := s.queueName(req, in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := s.backend.QueueConfig(name)
+	if err != nil {
+		return nil, errQueueDoesNotExist
+	}
+	stats, err := s.backend.Stats(memorybackend.URL(name))
+	if err != nil {
+		return nil, err
+	}
+	attrs := map[string]string{
+		"QueueArn":                              queueArn(name),
+		"ApproximateNumberOfMessages":           strconv.Itoa(stats.Visible),
+		"ApproximateNumberOfMessagesNotVisible": strconv.Itoa(stats.NotVisible),
+		"ApproximateNumberOfMessagesDelayed":    strconv.Itoa(stats.Delayed),
+		"VisibilityTimeout":                     strconv.Itoa(int(conf.VisibilityTimeout.Seconds())),
+		"DelaySeconds":                          strconv.Itoa(int(conf.Delay.Seconds())),
+	}
+	if strings.HasSuffix(name, ".fifo") {
+		attrs["FifoQueue"] = "true"
+	}
+	if conf.DeadLetterQueue != "" {
+		policy, err := json.Marshal(redrivePolicy{
+			DeadLetterTargetArn: queueArn(conf.DeadLetterQueue),
+			MaxReceiveCount:     number(conf.MaxReceiveCount),
+		})
+		if err != nil {
+			return nil, err
+		}
+		attrs["RedrivePolicy"] = string(policy)
+	}
+	out := getQueueAttributesOutput{Attributes: make(map[string]string)}
+	for key, val := range attrs {
+		for _, n := range in.AttributeNames {
+			if n == "All" || n == key {
+				out.Attributes[key] = val
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+type messageAttributeValue struct {
+	DataType    string
+	StringValue string `json:",omitempty"`
+	BinaryValue []byte `json:",omitempty"`
+}
+
+func messageAttributes(values map[string]messageAttributeValue) (map[string]sqsd.MessageAttribute, error) {
+	if len(values) > 10 {
+		return nil, invalidParameter("Number of message attributes [%d] exceeds the allowed maximum [10].", len(values))
+	}
+	attrs := make(map[string]sqsd.MessageAttribute, len(values))
+	for name, v := range values {
+		attr := sqsd.MessageAttribute{
+			DataType:    v.DataType,
+			StringValue: v.StringValue,
+			BinaryValue: v.BinaryValue,
+		}
+		switch {
+		case name == "":
+			return nil, invalidParameter("The message attribute name must not be empty.")
+		case attr.IsBinary():
+			if len(attr.BinaryValue) == 0 {
+				return nil, invalidParameter("The message attribute '%s' must contain a non-empty value of type 'Binary'.", name)
+			}
+		case attr.IsString(), attr.IsNumber():
+			if attr.StringValue == "" {
+				return nil, invalidParameter("The message attribute '%s' must contain a non-empty message attribute value for message attribute type '%s'.", name, attr.DataType)
+			}
+		default:
+			return nil, invalidParameter("The type of message attribute '%s' is invalid.", name)
+		}
+		attrs[name] = attr
+	}
+	return attrs, nil
+}
+
+type sendMessageInput struct {
+	QueueUrl               string
+	MessageBody            string
+	DelaySeconds           number
+	MessageAttributes      map[string]messageAttributeValue
+	MessageGroupId         string
+	MessageDeduplicationId string
+}
+
+type sendMessageOutput struct {
+	MessageId              string
+	MD5OfMessageBody       string
+	MD5OfMessageAttributes string `json:",omitempty"`
+}
+
+func (s *Server) sendMessage(ctx context.Context, req request, in sendMessageInput) (any, error) {
+	name, err := s.queueName(req, in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	return s.send(ctx, name, in)
+}
+
+func (s *Server) send(ctx context.Context, name string, in sendMessageInput) (sendMessageOutput, error) {
+	if in.MessageBody == "" {
+		return sendMessageOutput{}, missingParameter("MessageBody")
+	}
+	if err := checkRange("DelaySeconds", in.DelaySeconds, 0, 900); err != nil {
+		return sendMessageOutput{}, err
+	}
+	attrs, err := messageAttributes(in.MessageAttributes)
+	if err != nil {
+		return sendMessageOutput{}, err
+	}
+	id, err := s.backend.Send(ctx, sqsd.SendInput{
+		QueueURL:        memorybackend.URL(name),
+		Body:            in.MessageBody,
+		Attributes:      attrs,
+		Delay:           time.Duration(in.DelaySeconds) * time.Second,
+		MessageGroupID:  in.MessageGroupId,
+		DeduplicationID: in.MessageDeduplicationId,
+	})
+	if err != nil {
+		return sendMessageOutput{}, invalidParameter(err.Error())
+	}
+	return sendMessageOutput{
+		MessageId:              id,
+		MD5OfMessageBody:       md5OfBody(in.MessageBody),
+		MD5OfMessageAttributes: md5OfAttributes(attrs),
+	}, nil
+}
+
+type sendMessageBatchInput struct {
+	QueueUrl string
+	Entries  []struct {
+		Id string
+		sendMessageInput
+	}
+}
+
+type sendMessageBatchResultEntry struct {
+	Id string
+	sendMessageOutput
+}
+
+type batchResultErrorEntry struct {
+	Id          string
+	Code        string
+	Message     string
+	SenderFault bool
+}
+
+type sendMessageBatchOutput struct {
+	Successful []sendMessageBatchResultEntry
+	Failed     []batchResultErrorEntry
+}
+
+func newBatchResultErrorEntry(id string, err error) batchResultErrorEntry {
+	apiErr := asAPIError(err)
+	return batchResultErrorEntry{
+		Id:          id,
+		Code:        apiErr.Code,
+		Message:     apiErr.Message,
+		SenderFault: apiErr.Status < http.StatusInternalServerError,
+	}
+}
+
+var batchEntryIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,80}$`)
+
+// checkBatchEntries validates ids of batch request entries.
+func checkBatchEntries(ids []string) error {
+	switch {
+	case len(ids) == 0:
+		return &apiError{Status: http.StatusBadRequest, Code: "EmptyBatchRequest", Message: "There should be at least one entry in the request."}
+	case len(ids) > 10:
+		return &apiError{Status: http.StatusBadRequest, Code: "TooManyEntriesInBatchRequest", Message: "Maximum number of entries per request are 10."}
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !batchEntryIDPattern.MatchString(id) {
+			return &apiError{Status: http.StatusBadRequest, Code: "InvalidBatchEntryId", Message: "A batch entry id can only contain alphanumeric characters, hyphens and underscores."}
+		}
+		if seen[id] {
+			return &apiError{Status: http.StatusBadRequest, Code: "BatchEntryIdsNotDistinct", Message: "Id " + id + " repeated."}
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func (s *Server) sendMessageBatch(ctx context.Context, req request, in sendMessageBatchInput) (any, error) {
+	name, err := s.queueName(req, in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(in.Entries))
+	for _, e := range in.Entries {
+		ids = append(ids, e.Id)
+	}
+	if err := checkBatchEntries(ids); err != nil {
+		return nil, err
+	}
+	out := sendMessageBatchOutput{
+		Successful: []sendMessageBatchResultEntry{},
+		Failed:     []batchResultErrorEntry{},
+	}
+	for _, e := range in.Entries {
+		res, err := s.send(ctx, name, e.sendMessageInput)
+		if err != nil {
+			out.Failed = append(out.Failed, newBatchResultErrorEntry(e.Id, err))
+			continue
+		}
+		out.Successful = append(out.Successful, sendMessageBatchResultEntry{Id: e.Id, sendMessageOutput: res})
+	}
+	return out, nil
+}
+
+type receiveMessageInput struct {
+	QueueUrl                    string
+	MaxNumberOfMessages         number
+	WaitTimeSeconds             number
+	VisibilityTimeout           number
+	AttributeNames              []string
+	MessageSystemAttributeNames []string
+	MessageAttributeNames       []string
+}
+
+type message struct {
+	MessageId              string
+	ReceiptHandle          string
+	MD5OfBody              string
+	Body                   string
+	Attributes             map[string]string                `json:",omitempty"`
+	MD5OfMessageAttributes string                           `json:",omitempty"`
+	MessageAttributes      map[string]messageAttributeValue `json:",omitempty"`
+}
+
+type receiveMessageOutput struct {
+	Messages []message `json:",omitempty"`
+}
+
+func (s *Server) receiveMessage(ctx context.Context, req request, in receiveMessageInput) (any, error) {
+	name, err := s.queueName(req, in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	if in.MaxNumberOfMessages == 0 {
+		in.MaxNumberOfMessages = 1
+	}
+	for _, check := range []error{
+		checkRange("MaxNumberOfMessages", in.MaxNumberOfMessages, 1, 10),
+		checkRange("WaitTimeSeconds", in.WaitTimeSeconds, 0, 20),
+		checkRange("VisibilityTimeout", in.VisibilityTimeout, 0, 43200),
+	} {
+		if check != nil {
+			return nil, check
+		}
+	}
+	msgs, err := s.backend.Receive(ctx, sqsd.ReceiveInput{
+		QueueURL:          memorybackend.URL(name),
+		MaxMessages:       int(in.MaxNumberOfMessages),
+		WaitTime:          time.Duration(in.WaitTimeSeconds) * time.Second,
+		VisibilityTimeout: time.Duration(in.VisibilityTimeout) * time.Second,
+		SystemAttributes:  append(in.AttributeNames, in.MessageSystemAttributeNames...),
+		MessageAttributes: in.MessageAttributeNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var out receiveMessageOutput
+	for _, msg := range msgs {
+		m := message{
+			MessageId:              msg.ID,
+			ReceiptHandle:          msg.Receipt,
+			MD5OfBody:              md5OfBody(msg.Payload),
+			Body:                   msg.Payload,
+			Attributes:             msg.SystemAttributes,
+			MD5OfMessageAttributes: md5OfAttributes(msg.Attributes),
+		}
+		if len(msg.Attributes) > 0 {
+			m.MessageAttributes = make(map[string]messageAttributeValue, len(msg.Attributes))
+			for name, attr := range msg.Attributes {
+				m.MessageAttributes[name] = messageAttributeValue{
+					DataType:    attr.DataType,
+					StringValue: attr.StringValue,
+					BinaryValue: attr.BinaryValue,
+				}
+			}
+		}
+		out.Messages = append(out.Messages, m)
+	}
+	return out, nil
+}
+
+type deleteMessageInput struct {
+	QueueUrl      string
+	ReceiptHandle string
+}
+
+func receiptHandleIsInvalid(receipt string) *apiError {
+	return &apiError{Status: http.StatusBadRequest, Code: "ReceiptHandleIsInvalid", Message: "The input receipt handle \"" + receipt + "\" is not a valid receipt handle."}
+}
+
+func (s *Server) deleteMessage(ctx context.Context, req request, in deleteMessageInput) (any, error) {
+	name, err := s.queueName(req, in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	if in.ReceiptHandle == "" {
+		return nil, missingParameter("ReceiptHandle")
+	}
+	errs, err := s.backend.DeleteMessages(ctx, memorybackend.URL(name), []string{in.ReceiptHandle})
+	if err != nil {
+		return nil, err
+	}
+	if errs[0] != nil {
+		return nil, receiptHandleIsInvalid(in.ReceiptHandle)
+	}
+	return struct{}{}, nil
+}
+
+type deleteMessageBatchInput struct {
+	QueueUrl string
+	Entries  []struct {
+		Id            string
+		ReceiptHandle string
+	}
+}
+
+type deleteMessageBatchResultEntry struct {
+	Id string
+}
+
+type deleteMessageBatchOutput struct {
+	Successful []deleteMessageBatchResultEntry
+	Failed     []batchResultErrorEntry
+}
+
+func (s *Server) deleteMessageBatch(ctx context.Context, req request, in deleteMessageBatchInput) (any, error) {
+	name, err := s.queueName(req, in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(in.Entries))
+	receipts := make([]string, 0, len(in.Entries))
+	for _, e := range in.Entries {
+		ids = append(ids, e.Id)
+		receipts = append(receipts, e.ReceiptHandle)
+	}
+	if err := checkBatchEntries(ids); err != nil {
+		return nil, err
+	}
+	errs, err := s.backend.DeleteMessages(ctx, memorybackend.URL(name), receipts)
+	if err != nil {
+		return nil, err
+	}
+	out := deleteMessageBatchOutput{
+		Successful: []deleteMessageBatchResultEntry{},
+		Failed:     []batchResultErrorEntry{},
+	}
+	for i, id := range ids {
+		if errs[i] != nil {
+			out.Failed = append(out.Failed, newBatchResultErrorEntry(id, receiptHandleIsInvalid(receipts[i])))
+			continue
+		}
+		out.Successful = append(out.Successful, deleteMessageBatchResultEntry{Id: id})
+	}
+	return out, nil
+}
+
+type changeMessageVisibilityInput struct {
+	QueueUrl          string
+	ReceiptHandle     string
+	VisibilityTimeout number
+}
+
+func (s *Server) changeMessageVisibility(ctx context.Context, req request, in changeMessageVisibilityInput) (any, error) {
+	name, err := s.queueName(req, in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	if in.ReceiptHandle == "" {
+		return nil, missingParameter("ReceiptHandle")
+	}
+	if err := checkRange("VisibilityTimeout", in.VisibilityTimeout, 0, 43200); err != nil {
+		return nil, err
+	}
+	if err := s.backend.ChangeVisibility(ctx, memorybackend.URL(name), in.ReceiptHandle, time.Duration(in.VisibilityTimeout)*time.Second); err != nil {
+		return nil, receiptHandleIsInvalid(in.ReceiptHandle)
+	}
+	return struct{}{}, nil
+}
+
+// sortedKeys returns keys of map in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,277 @@
+package devqueue
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	sqsd "github.com/taiyoh/sqsd/v2"
+)
+
+const xmlNamespace = "http://queue.amazonaws.com/doc/2012-11-05/"
+
+// decodeQuery reads parameters of query protocol into v.
+// Parameters are shaped as JSON protocol at first, for example,
+//
+//	Attribute.1.Name=VisibilityTimeout&Attribute.1.Value=10   -> {"Attributes": {"VisibilityTimeout": "10"}}
+//	AttributeName.1=All                                       -> {"AttributeNames": ["All"]}
+//	SendMessageBatchRequestEntry.1.Id=a                       -> {"Entries": [{"Id": "a"}]}
+func decodeQuery(form url.Values, v any) error {
+	tree := make(map[string]any)
+	for key, values := range form {
+		if len(values) == 0 {
+			continue
+		}
+		node := tree
+		parts := strings.Split(key, ".")
+		for _, p := range parts[:len(parts)-1] {
+			child, ok := node[p].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				node[p] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = values[0]
+	}
+	b, err := json.Marshal(shapeQuery(tree))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func shapeQuery(node map[string]any) map[string]any {
+	out := make(map[string]any, len(node))
+	for key, val := range node {
+		children, ok := val.(map[string]any)
+		switch {
+		case !ok:
+			out[key] = val
+		case key == "Attribute" || key == "MessageAttribute" || key == "MessageSystemAttribute":
+			m := make(map[string]any)
+			for _, entry := range indexed(children) {
+				e, ok := entry.(map[string]any)
+				if !ok {
+					continue
+				}
+				name, _ := e["Name"].(string)
+				value := e["Value"]
+				if vm, ok := value.(map[string]any); ok {
+					value = shapeQuery(vm)
+				}
+				m[name] = value
+			}
+			out[key+"s"] = m
+		case strings.HasSuffix(key, "BatchRequestEntry"):
+			entries := make([]any, 0, len(children))
+			for _, entry := range indexed(children) {
+				if e, ok := entry.(map[string]any); ok {
+					entries = append(entries, shapeQuery(e))
+				}
+			}
+			out["Entries"] = entries
+		default:
+			out[key+"s"] = indexed(children)
+		}
+	}
+	return out
+}
+
+// indexed returns values of flattened list in order of index.
+func indexed(m map[string]any) []any {
+	type item struct {
+		idx int
+		val any
+	}
+	items := make([]item, 0, len(m))
+	for key, val := range m {
+		idx, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		items = append(items, item{idx: idx, val: val})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].idx < items[j].idx })
+	values := make([]any, 0, len(items))
+	for _, it := range items {
+		values = append(values, it.val)
+	}
+	return values
+}
+
+// listMember returns element name of list member in XML response.
+func listMember(action, key string) string {
+	switch key {
+	case "Messages":
+		return "Message"
+	case "QueueUrls":
+		return "QueueUrl"
+	case "Failed":
+		return "BatchResultErrorEntry"
+	case "Successful":
+		return action + "ResultEntry"
+	}
+	return "member"
+}
+
+// writeXML writes output of action in query protocol.
+// Output is the same value as JSON protocol, and it is converted to XML elements.
+func writeXML(w http.ResponseWriter, action string, out any) {
+	b, err := json.Marshal(out)
+	if err != nil {
+		writeXMLError(w, err)
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		writeXMLError(w, err)
+		return
+	}
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	root := xml.StartElement{Name: xml.Name{Local: action + "Response"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: xmlNamespace}}}
+	_ = enc.EncodeToken(root)
+	if len(fields) > 0 {
+		encodeElement(enc, action+"Result", func() { encodeFields(enc, action, fields) })
+	}
+	encodeElement(enc, "ResponseMetadata", func() { encodeText(enc, "RequestId", newRequestID()) })
+	_ = enc.EncodeToken(root.End())
+	_ = enc.Flush()
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func encodeFields(enc *xml.Encoder, action string, fields map[string]any) {
+	for _, key := range sortedKeys(fields) {
+		switch val := fields[key].(type) {
+		case map[string]any:
+			if key != "Attributes" && key != "MessageAttributes" {
+				encodeElement(enc, key, func() { encodeFields(enc, action, val) })
+				continue
+			}
+			// maps are flattened as list of Name and Value.
+			for _, name := range sortedKeys(val) {
+				encodeElement(enc, strings.TrimSuffix(key, "s"), func() {
+					encodeText(enc, "Name", name)
+					if m, ok := val[name].(map[string]any); ok {
+						encodeElement(enc, "Value", func() { encodeFields(enc, action, m) })
+					} else {
+						encodeText(enc, "Value", scalar(val[name]))
+					}
+				})
+			}
+		case []any:
+			member := listMember(action, key)
+			for _, item := range val {
+				if m, ok := item.(map[string]any); ok {
+					encodeElement(enc, member, func() { encodeFields(enc, action, m) })
+				} else {
+					encodeText(enc, member, scalar(item))
+				}
+			}
+		case nil:
+		default:
+			encodeText(enc, key, scalar(val))
+		}
+	}
+}
+
+func scalar(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+func encodeElement(enc *xml.Encoder, name string, inner func()) {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	_ = enc.EncodeToken(start)
+	inner()
+	_ = enc.EncodeToken(start.End())
+}
+
+func encodeText(enc *xml.Encoder, name, text string) {
+	encodeElement(enc, name, func() { _ = enc.EncodeToken(xml.CharData(text)) })
+}
+
+type xmlErrorResponse struct {
+	XMLName xml.Name `xml:"ErrorResponse"`
+	Error   struct {
+		Type    string `xml:"Type"`
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+	RequestID string `xml:"RequestId"`
+}
+
+func writeXMLError(w http.ResponseWriter, err error) {
+	apiErr := asAPIError(err)
+	var res xmlErrorResponse
+	res.Error.Type = "Sender"
+	if apiErr.Status >= http.StatusInternalServerError {
+		res.Error.Type = "Receiver"
+	}
+	res.Error.Code = apiErr.Code
+	res.Error.Message = apiErr.Message
+	res.RequestID = newRequestID()
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(apiErr.Status)
+	_ = xml.NewEncoder(w).Encode(res)
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+func md5OfBody(body string) string {
+	sum := md5.Sum([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// md5OfAttributes calculates digest of message attributes in the same way as SQS,
+// so that SDKs can verify MD5OfMessageAttributes.
+func md5OfAttributes(attrs map[string]sqsd.MessageAttribute) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	h := md5.New()
+	writeBytes := func(b []byte) {
+		_ = binary.Write(h, binary.BigEndian, uint32(len(b)))
+		_, _ = h.Write(b)
+	}
+	for _, name := range sortedKeys(attrs) {
+		attr := attrs[name]
+		writeBytes([]byte(name))
+		writeBytes([]byte(attr.DataType))
+		if attr.IsBinary() {
+			_, _ = h.Write([]byte{2})
+			writeBytes(attr.BinaryValue)
+		} else {
+			_, _ = h.Write([]byte{1})
+			writeBytes([]byte(attr.StringValue))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
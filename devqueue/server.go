@@ -0,0 +1,191 @@
+// Package devqueue serves a subset of Amazon SQS API on in-memory queues for local development.
+//
+// Both JSON protocol (used by recent AWS SDKs) and query protocol (used by AWS CLI v1 and older SDKs) are accepted.
+// Supported actions are CreateQueue, DeleteQueue, GetQueueUrl, ListQueues, GetQueueAttributes,
+// SendMessage, SendMessageBatch, ReceiveMessage, DeleteMessage, DeleteMessageBatch and ChangeMessageVisibility.
+package devqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	memorybackend "github.com/taiyoh/sqsd/v2/backend/memory"
+)
+
+const (
+	// AccountID is the account id which is included in queue URL and ARN.
+	AccountID = "000000000000"
+	// Region is the region which is included in queue ARN.
+	Region = "us-east-1"
+)
+
+// Server is http.Handler which serves SQS API.
+type Server struct {
+	backend *memorybackend.Backend
+}
+
+// New returns Server object which stores messages to backend.
+func New(backend *memorybackend.Backend) *Server {
+	return &Server{
+		backend: backend,
+	}
+}
+
+// apiError is the error response of SQS API.
+type apiError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func invalidParameter(format string, args ...any) *apiError {
+	return &apiError{Status: http.StatusBadRequest, Code: "InvalidParameterValue", Message: fmt.Sprintf(format, args...)}
+}
+
+func missingParameter(name string) *apiError {
+	return &apiError{Status: http.StatusBadRequest, Code: "MissingParameter", Message: fmt.Sprintf("The request must contain the parameter %s.", name)}
+}
+
+var errQueueDoesNotExist = &apiError{
+	Status:  http.StatusBadRequest,
+	Code:    "QueueDoesNotExist",
+	Message: "The specified queue does not exist.",
+}
+
+// request is an API call which is decoded from either protocol.
+type request struct {
+	action string
+	// decode reads parameters of action into v.
+	decode func(v any) error
+	// baseURL is used for building queue URL.
+	baseURL string
+	// pathQueueURL is the queue URL which is given as request path in query protocol.
+	pathQueueURL string
+}
+
+// ServeHTTP handles API request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	req := request{baseURL: scheme + "://" + r.Host}
+	if target := r.Header.Get("X-Amz-Target"); target != "" {
+		action, ok := strings.CutPrefix(target, "AmazonSQS.")
+		if !ok {
+			writeJSONError(w, &apiError{Status: http.StatusBadRequest, Code: "InvalidAction", Message: "unknown target: " + target})
+			return
+		}
+		req.action = action
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeJSONError(w, invalidParameter("failed to read request body: %v", err))
+			return
+		}
+		req.decode = func(v any) error {
+			if len(body) == 0 {
+				return nil
+			}
+			return json.Unmarshal(body, v)
+		}
+		out, err := s.call(r.Context(), req)
+		if err != nil {
+			writeJSONError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, out)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeXMLError(w, invalidParameter("failed to parse request: %v", err))
+		return
+	}
+	req.action = r.Form.Get("Action")
+	req.decode = func(v any) error {
+		return decodeQuery(r.Form, v)
+	}
+	if r.URL.Path != "" && r.URL.Path != "/" {
+		req.pathQueueURL = req.baseURL + r.URL.Path
+	}
+	out, err := s.call(r.Context(), req)
+	if err != nil {
+		writeXMLError(w, err)
+		return
+	}
+	writeXML(w, req.action, out)
+}
+
+func (s *Server) call(ctx context.Context, req request) (any, error) {
+	action, ok := actions[req.action]
+	if !ok {
+		return nil, &apiError{Status: http.StatusBadRequest, Code: "InvalidAction", Message: fmt.Sprintf("The action %s is not valid for this endpoint.", req.action)}
+	}
+	return action(s, ctx, req)
+}
+
+// queueURL returns URL of queue for the request.
+func (req request) queueURL(name string) string {
+	return req.baseURL + "/" + AccountID + "/" + name
+}
+
+// queueName returns name of existing queue from queue URL.
+func (s *Server) queueName(req request, queueURL string) (string, error) {
+	if queueURL == "" {
+		queueURL = req.pathQueueURL
+	}
+	if queueURL == "" {
+		return "", missingParameter("QueueUrl")
+	}
+	u, err := url.Parse(queueURL)
+	if err != nil || u.Scheme == memorybackend.Scheme {
+		return "", invalidParameter("invalid queue URL: %s", queueURL)
+	}
+	name, err := memorybackend.QueueName(queueURL)
+	if err != nil {
+		return "", invalidParameter("invalid queue URL: %s", queueURL)
+	}
+	if _, err := s.backend.QueueConfig(name); err != nil {
+		return "", errQueueDoesNotExist
+	}
+	return name, nil
+}
+
+func queueArn(name string) string {
+	return "arn:aws:sqs:" + Region + ":" + AccountID + ":" + name
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func asAPIError(err error) *apiError {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return &apiError{Status: http.StatusInternalServerError, Code: "InternalFailure", Message: err.Error()}
+}
+
+func writeJSONError(w http.ResponseWriter, err error) {
+	apiErr := asAPIError(err)
+	writeJSON(w, apiErr.Status, map[string]string{
+		"__type":  "com.amazonaws.sqs#" + apiErr.Code,
+		"message": apiErr.Message,
+	})
+}
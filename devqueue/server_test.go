@@ -0,0 +1,294 @@
+package devqueue
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	memorybackend "github.com/taiyoh/sqsd/v2/backend/memory"
+)
+
+func newTestClient(t *testing.T) (*sqs.Client, *httptest.Server) {
+	srv := httptest.NewServer(New(memorybackend.New()))
+	t.Cleanup(srv.Close)
+	client := sqs.New(sqs.Options{
+		Region:       Region,
+		Credentials:  credentials.NewStaticCredentialsProvider("dummy", "dummy", ""),
+		BaseEndpoint: aws.String(srv.URL),
+	})
+	return client, srv
+}
+
+func TestServerJSONProtocol(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newTestClient(t)
+
+	created, err := client.CreateQueue(ctx, &sqs.CreateQueueInput{
+		QueueName:  aws.String("jobs"),
+		Attributes: map[string]string{"VisibilityTimeout": "10"},
+	})
+	require.NoError(t, err)
+	queueURL := aws.ToString(created.QueueUrl)
+	assert.Equal(t, srv.URL+"/"+AccountID+"/jobs", queueURL)
+
+	// creating the same queue again returns its URL.
+	created, err = client.CreateQueue(ctx, &sqs.CreateQueueInput{QueueName: aws.String("jobs")})
+	require.NoError(t, err)
+	assert.Equal(t, queueURL, aws.ToString(created.QueueUrl))
+
+	// SDK verifies MD5 of body and attributes.
+	sent, err := client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    &queueURL,
+		MessageBody: aws.String("hello"),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"Kind":  {DataType: aws.String("String"), StringValue: aws.String("user")},
+			"Count": {DataType: aws.String("Number"), StringValue: aws.String("3")},
+			"Raw":   {DataType: aws.String("Binary"), BinaryValue: []byte{0, 1, 2}},
+		},
+	})
+	require.NoError(t, err)
+
+	batch, err := client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+		QueueUrl: &queueURL,
+		Entries: []types.SendMessageBatchRequestEntry{
+			{Id: aws.String("a"), MessageBody: aws.String("first")},
+			{Id: aws.String("b"), MessageBody: aws.String("second")},
+			{Id: aws.String("c"), MessageBody: aws.String("delayed"), DelaySeconds: 900},
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, batch.Successful, 3)
+	assert.Empty(t, batch.Failed)
+
+	attrs, err := client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       &queueURL,
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameAll},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "3", attrs.Attributes["ApproximateNumberOfMessages"])
+	assert.Equal(t, "1", attrs.Attributes["ApproximateNumberOfMessagesDelayed"])
+	assert.Equal(t, "10", attrs.Attributes["VisibilityTimeout"])
+	assert.Equal(t, "arn:aws:sqs:"+Region+":"+AccountID+":jobs", attrs.Attributes["QueueArn"])
+
+	received, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:              &queueURL,
+		MaxNumberOfMessages:   10,
+		AttributeNames:        []types.QueueAttributeName{types.QueueAttributeNameAll},
+		MessageAttributeNames: []string{"All"},
+	})
+	require.NoError(t, err)
+	require.Len(t, received.Messages, 3)
+	first := received.Messages[0]
+	assert.Equal(t, aws.ToString(sent.MessageId), aws.ToString(first.MessageId))
+	assert.Equal(t, "hello", aws.ToString(first.Body))
+	assert.Equal(t, "1", first.Attributes["ApproximateReceiveCount"])
+	assert.Equal(t, []byte{0, 1, 2}, first.MessageAttributes["Raw"].BinaryValue)
+	assert.Equal(t, "user", aws.ToString(first.MessageAttributes["Kind"].StringValue))
+
+	_, err = client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          &queueURL,
+		ReceiptHandle:     first.ReceiptHandle,
+		VisibilityTimeout: 0,
+	})
+	require.NoError(t, err)
+
+	_, err = client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      &queueURL,
+		ReceiptHandle: first.ReceiptHandle,
+	})
+	require.NoError(t, err)
+
+	deleted, err := client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+		QueueUrl: &queueURL,
+		Entries: []types.DeleteMessageBatchRequestEntry{
+			{Id: aws.String("a"), ReceiptHandle: received.Messages[1].ReceiptHandle},
+			{Id: aws.String("b"), ReceiptHandle: aws.String("unknown")},
+		},
+	})
+	require.NoError(t, err)
+	if assert.Len(t, deleted.Successful, 1) {
+		assert.Equal(t, "a", aws.ToString(deleted.Successful[0].Id))
+	}
+	if assert.Len(t, deleted.Failed, 1) {
+		assert.Equal(t, "b", aws.ToString(deleted.Failed[0].Id))
+		assert.Equal(t, "ReceiptHandleIsInvalid", aws.ToString(deleted.Failed[0].Code))
+		assert.True(t, deleted.Failed[0].SenderFault)
+	}
+
+	_, err = client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      &queueURL,
+		ReceiptHandle: first.ReceiptHandle,
+	})
+	assert.ErrorContains(t, err, "ReceiptHandleIsInvalid")
+
+	_, err = client.DeleteQueue(ctx, &sqs.DeleteQueueInput{QueueUrl: &queueURL})
+	require.NoError(t, err)
+
+	_, err = client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{QueueUrl: &queueURL})
+	var notExist *types.QueueDoesNotExist
+	assert.True(t, errors.As(err, &notExist), err)
+}
+
+func TestServerRedrivePolicy(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestClient(t)
+
+	dlq, err := client.CreateQueue(ctx, &sqs.CreateQueueInput{QueueName: aws.String("jobs-dlq")})
+	require.NoError(t, err)
+	created, err := client.CreateQueue(ctx, &sqs.CreateQueueInput{
+		QueueName: aws.String("jobs"),
+		Attributes: map[string]string{
+			"RedrivePolicy": `{"deadLetterTargetArn":"arn:aws:sqs:` + Region + `:` + AccountID + `:jobs-dlq","maxReceiveCount":"1"}`,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = client.SendMessage(ctx, &sqs.SendMessageInput{QueueUrl: created.QueueUrl, MessageBody: aws.String("hello")})
+	require.NoError(t, err)
+	received, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{QueueUrl: created.QueueUrl})
+	require.NoError(t, err)
+	require.Len(t, received.Messages, 1)
+	_, err = client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:      created.QueueUrl,
+		ReceiptHandle: received.Messages[0].ReceiptHandle,
+	})
+	require.NoError(t, err)
+
+	received, err = client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{QueueUrl: created.QueueUrl})
+	require.NoError(t, err)
+	assert.Empty(t, received.Messages)
+	received, err = client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{QueueUrl: dlq.QueueUrl})
+	require.NoError(t, err)
+	assert.Len(t, received.Messages, 1)
+
+	_, err = client.CreateQueue(ctx, &sqs.CreateQueueInput{
+		QueueName:  aws.String("invalid"),
+		Attributes: map[string]string{"Unknown": "1"},
+	})
+	assert.ErrorContains(t, err, "InvalidParameterValue")
+}
+
+func TestServerQueryProtocol(t *testing.T) {
+	_, srv := newTestClient(t)
+
+	post := func(params url.Values) (int, string) {
+		res, err := http.PostForm(srv.URL+"/", params)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, string(b)
+	}
+
+	status, body := post(url.Values{
+		"Action":            {"CreateQueue"},
+		"QueueName":         {"jobs.fifo"},
+		"Attribute.1.Name":  {"FifoQueue"},
+		"Attribute.1.Value": {"true"},
+	})
+	require.Equal(t, http.StatusOK, status, body)
+	var created struct {
+		QueueURL string `xml:"CreateQueueResult>QueueUrl"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(body), &created))
+	assert.Equal(t, srv.URL+"/"+AccountID+"/jobs.fifo", created.QueueURL)
+
+	status, body = post(url.Values{
+		"Action":                            {"SendMessageBatch"},
+		"QueueUrl":                          {created.QueueURL},
+		"SendMessageBatchRequestEntry.1.Id": {"a"},
+		"SendMessageBatchRequestEntry.1.MessageBody":                          {"first"},
+		"SendMessageBatchRequestEntry.1.MessageGroupId":                       {"g"},
+		"SendMessageBatchRequestEntry.1.MessageDeduplicationId":               {"1"},
+		"SendMessageBatchRequestEntry.1.MessageAttribute.1.Name":              {"Kind"},
+		"SendMessageBatchRequestEntry.1.MessageAttribute.1.Value.DataType":    {"String"},
+		"SendMessageBatchRequestEntry.1.MessageAttribute.1.Value.StringValue": {"user"},
+		"SendMessageBatchRequestEntry.2.Id":                                   {"b"},
+		"SendMessageBatchRequestEntry.2.MessageBody":                          {"second"},
+	})
+	require.Equal(t, http.StatusOK, status, body)
+	var sent struct {
+		Successful []string `xml:"SendMessageBatchResult>SendMessageBatchResultEntry>Id"`
+		Failed     []struct {
+			ID          string `xml:"Id"`
+			Code        string `xml:"Code"`
+			SenderFault bool   `xml:"SenderFault"`
+		} `xml:"SendMessageBatchResult>BatchResultErrorEntry"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(body), &sent))
+	assert.Equal(t, []string{"a"}, sent.Successful)
+	if assert.Len(t, sent.Failed, 1) {
+		assert.Equal(t, "b", sent.Failed[0].ID)
+		assert.Equal(t, "InvalidParameterValue", sent.Failed[0].Code)
+		assert.True(t, sent.Failed[0].SenderFault)
+	}
+
+	// queue URL is also accepted as request path.
+	path := strings.TrimPrefix(created.QueueURL, srv.URL)
+	res, err := http.PostForm(srv.URL+path, url.Values{
+		"Action":                 {"ReceiveMessage"},
+		"AttributeName.1":        {"MessageGroupId"},
+		"MessageAttributeName.1": {"All"},
+	})
+	require.NoError(t, err)
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode, string(b))
+	var received struct {
+		Messages []struct {
+			Body       string `xml:"Body"`
+			MD5OfBody  string `xml:"MD5OfBody"`
+			Attributes []struct {
+				Name  string `xml:"Name"`
+				Value string `xml:"Value"`
+			} `xml:"Attribute"`
+			MessageAttributes []struct {
+				Name  string `xml:"Name"`
+				Value struct {
+					DataType    string `xml:"DataType"`
+					StringValue string `xml:"StringValue"`
+				} `xml:"Value"`
+			} `xml:"MessageAttribute"`
+		} `xml:"ReceiveMessageResult>Message"`
+	}
+	require.NoError(t, xml.Unmarshal(b, &received))
+	require.Len(t, received.Messages, 1)
+	msg := received.Messages[0]
+	assert.Equal(t, "first", msg.Body)
+	assert.Equal(t, md5OfBody("first"), msg.MD5OfBody)
+	if assert.Len(t, msg.Attributes, 1) {
+		assert.Equal(t, "MessageGroupId", msg.Attributes[0].Name)
+		assert.Equal(t, "g", msg.Attributes[0].Value)
+	}
+	if assert.Len(t, msg.MessageAttributes, 1) {
+		assert.Equal(t, "Kind", msg.MessageAttributes[0].Name)
+		assert.Equal(t, "user", msg.MessageAttributes[0].Value.StringValue)
+	}
+
+	status, body = post(url.Values{
+		"Action":   {"GetQueueAttributes"},
+		"QueueUrl": {srv.URL + "/" + AccountID + "/unknown"},
+	})
+	assert.Equal(t, http.StatusBadRequest, status)
+	var errRes xmlErrorResponse
+	require.NoError(t, xml.Unmarshal([]byte(body), &errRes))
+	assert.Equal(t, "QueueDoesNotExist", errRes.Error.Code)
+	assert.Equal(t, "Sender", errRes.Error.Type)
+
+	status, _ = post(url.Values{"Action": {"PurgeQueue"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
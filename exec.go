@@ -0,0 +1,232 @@
+package sqsd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ExecInvoker invokes worker process by running command per message.
+// Payload is given as stdin, and headers of HTTPInvoker are given as environment variables,
+// such as SQSD_MSGID, SQSD_RECEIVE_COUNT and SQSD_ATTR_<message-attribute-name>.
+//
+// Exit code 0 means success, and other exit codes are mapped to actions by policies.
+// Exit codes which are not matched to any policies are retried.
+type ExecInvoker struct {
+	path        string
+	args        []string
+	timeout     time.Duration
+	dir         string
+	allowEnv    []string
+	envAllowed  bool
+	policies    []StatusPolicy
+	killDelay   time.Duration
+	outputLimit int
+}
+
+type execInvokerParams struct {
+	policies    []StatusPolicy
+	dir         string
+	allowEnv    []string
+	envAllowed  bool
+	killDelay   time.Duration
+	outputLimit int
+}
+
+// ExecInvokerParameter sets parameter to ExecInvoker by functional option pattern.
+type ExecInvokerParameter func(*execInvokerParams)
+
+// ExecExitCodePolicies maps exit codes to actions, with the same format as status policies.
+//
+//	75:retry:30s,3:retain,64-78:dead-letter
+func ExecExitCodePolicies(policies ...StatusPolicy) ExecInvokerParameter {
+	return func(p *execInvokerParams) {
+		p.policies = append(p.policies, policies...)
+	}
+}
+
+// ExecWorkDir sets working directory of command.
+func ExecWorkDir(dir string) ExecInvokerParameter {
+	return func(p *execInvokerParams) {
+		p.dir = dir
+	}
+}
+
+// ExecEnvAllowlist restricts environment variables of sqsd which are passed to command.
+// By default, all environment variables are passed.
+func ExecEnvAllowlist(names ...string) ExecInvokerParameter {
+	return func(p *execInvokerParams) {
+		p.allowEnv = append(p.allowEnv, names...)
+		p.envAllowed = true
+	}
+}
+
+// ExecKillDelay sets the duration between SIGTERM and SIGKILL when command exceeds timeout. default is 5 seconds.
+func ExecKillDelay(d time.Duration) ExecInvokerParameter {
+	return func(p *execInvokerParams) {
+		p.killDelay = d
+	}
+}
+
+// ExecOutputLimit sets the size of stdout and stderr which are written to log. default is 4096 bytes.
+func ExecOutputLimit(n int) ExecInvokerParameter {
+	return func(p *execInvokerParams) {
+		p.outputLimit = n
+	}
+}
+
+// NewExecInvoker returns ExecInvoker instance.
+// rawurl is "exec:///path/to/command", and arguments are given as "arg" query.
+//
+//	exec:///usr/local/bin/worker?arg=--verbose&arg=jobs
+func NewExecInvoker(rawurl string, dur time.Duration, params ...ExecInvokerParameter) (*ExecInvoker, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "exec" || u.Host != "" || u.Path == "" {
+		return nil, fmt.Errorf("exec URL must be exec:///path/to/command: %s", rawurl)
+	}
+	p := execInvokerParams{
+		killDelay:   5 * time.Second,
+		outputLimit: 4096,
+	}
+	for _, fn := range params {
+		fn(&p)
+	}
+	return &ExecInvoker{
+		path:        u.Path,
+		args:        u.Query()["arg"],
+		timeout:     dur,
+		dir:         p.dir,
+		allowEnv:    p.allowEnv,
+		envAllowed:  p.envAllowed,
+		policies:    p.policies,
+		killDelay:   p.killDelay,
+		outputLimit: p.outputLimit,
+	}, nil
+}
+
+// ExitCodeError shows that command exits with non-zero code.
+type ExitCodeError struct {
+	ExitCode int
+	Stderr   string
+}
+
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("command exits with code %d", e.ExitCode)
+}
+
+// Invoke runs command with message.
+func (ivk *ExecInvoker) Invoke(ctx context.Context, q Message) error {
+	if ivk.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ivk.timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, ivk.path, ivk.args...)
+	cmd.Dir = ivk.dir
+	cmd.Env = append(ivk.environ(), messageEnv(q)...)
+	cmd.Stdin = strings.NewReader(q.Payload)
+	stdout := &cappedBuffer{limit: ivk.outputLimit}
+	stderr := &cappedBuffer{limit: ivk.outputLimit}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	// process is killed if it does not exit in killDelay after SIGTERM.
+	cmd.WaitDelay = ivk.killDelay
+
+	startedAt := time.Now()
+	err := cmd.Run()
+	logger := getLogger().With(
+		"message_id", q.ID,
+		"exit_code", cmd.ProcessState.ExitCode(),
+		"duration", time.Since(startedAt).String(),
+		"stdout", stdout.String(),
+		"stderr", stderr.String())
+	if ctx.Err() != nil {
+		logger.Warn("command is terminated")
+		return fmt.Errorf("%w: %w", ctx.Err(), err)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		if err != nil {
+			return err
+		}
+		logger.Debug("command is succeeded")
+		return nil
+	}
+	logger.Info("command is failed")
+	code := exitErr.ExitCode()
+	return matchPolicy(ivk.policies, code, StatusActionRetry).wrap(&ExitCodeError{ExitCode: code, Stderr: stderr.String()})
+}
+
+// environ returns environment variables of sqsd which are passed to command.
+func (ivk *ExecInvoker) environ() []string {
+	if !ivk.envAllowed {
+		return os.Environ()
+	}
+	env := make([]string, 0, len(ivk.allowEnv))
+	for _, name := range ivk.allowEnv {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	return env
+}
+
+// messageEnv converts headers of HTTPInvoker to environment variables, such as X-Aws-Sqsd-Msgid to SQSD_MSGID.
+func messageEnv(q Message) []string {
+	h := make(http.Header)
+	setSqsdHeaders(h, q)
+	env := make([]string, 0, len(h))
+	for name, values := range h {
+		env = append(env, "SQSD_"+envName(strings.TrimPrefix(name, "X-Aws-Sqsd-"))+"="+values[0])
+	}
+	return env
+}
+
+// cappedBuffer keeps output up to limit bytes, and discards the rest.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if rest := b.limit - b.buf.Len(); rest < len(p) {
+		b.truncated = true
+		if rest > 0 {
+			b.buf.Write(p[:rest])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "...(truncated)"
+	}
+	return b.buf.String()
+}
+
+// envName converts header name to the name of environment variable, such as "X-Aws-Sqsd-Msgid" to "X_AWS_SQSD_MSGID".
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(name))
+}
@@ -0,0 +1,126 @@
+package sqsd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestScript(t *testing.T, script string) string {
+	path := filepath.Join(t.TempDir(), "worker.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	return path
+}
+
+func TestExecInvoker(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := writeTestScript(t, `
+echo "$SQSD_MSGID $SQSD_QUEUE $SQSD_ATTR_TRACE_ID $1 $(pwd) ${SQSD_TEST_SECRET:-none}" > `+out+`
+cat >> `+out+`
+case "$SQSD_ATTR_EXIT" in
+  "") exit 0 ;;
+  *) echo "failed by $SQSD_ATTR_EXIT" >&2; exit "$SQSD_ATTR_EXIT" ;;
+esac
+`)
+	t.Setenv("SQSD_TEST_SECRET", "secret")
+	ivk, err := NewExecInvoker("exec://"+script+"?arg=first", time.Second,
+		ExecWorkDir(dir),
+		ExecEnvAllowlist("PATH"),
+		ExecExitCodePolicies(
+			StatusPolicy{Min: 3, Max: 3, Action: StatusActionRetain},
+			StatusPolicy{Min: 75, Max: 75, Action: StatusActionRetry, Delay: 30 * time.Second},
+			StatusPolicy{Min: 64, Max: 78, Action: StatusActionDeadLetter},
+		))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	msg := Message{
+		ID:       "msg-1",
+		Payload:  `{"hello":"world"}`,
+		QueueURL: "http://localhost:9324/000000000000/my-queue",
+		Attributes: map[string]MessageAttribute{
+			"trace.id": {DataType: "String", StringValue: "abc"},
+		},
+	}
+	require.NoError(t, ivk.Invoke(ctx, msg))
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "msg-1 my-queue abc first "+dir+" none\n"+`{"hello":"world"}`, string(b))
+
+	withExit := func(code string) Message {
+		m := msg
+		m.Attributes = map[string]MessageAttribute{"exit": {DataType: "String", StringValue: code}}
+		return m
+	}
+	err = ivk.Invoke(ctx, withExit("3"))
+	assert.ErrorIs(t, err, ErrRetainMessage)
+
+	err = ivk.Invoke(ctx, withExit("75"))
+	var retryErr *RetryAfterError
+	if assert.ErrorAs(t, err, &retryErr) {
+		assert.Equal(t, 30*time.Second, retryErr.After)
+	}
+
+	err = ivk.Invoke(ctx, withExit("70"))
+	assert.ErrorIs(t, err, ErrDeadLetter)
+
+	err = ivk.Invoke(ctx, withExit("1"))
+	var exitErr *ExitCodeError
+	if assert.ErrorAs(t, err, &exitErr) {
+		assert.Equal(t, 1, exitErr.ExitCode)
+		assert.Equal(t, "failed by 1\n", exitErr.Stderr)
+	}
+	assert.NotErrorIs(t, err, ErrDeadLetter)
+	assert.NotErrorIs(t, err, ErrRetainMessage)
+}
+
+func TestExecInvokerTimeout(t *testing.T) {
+	// SIGTERM is ignored, so that process is killed after kill delay.
+	script := writeTestScript(t, `
+trap '' TERM
+echo "started"
+sleep 10 &
+wait
+`)
+	ivk, err := NewExecInvoker("exec://"+script, 100*time.Millisecond, ExecKillDelay(100*time.Millisecond))
+	require.NoError(t, err)
+
+	startedAt := time.Now()
+	err = ivk.Invoke(context.Background(), Message{ID: "msg-1"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(startedAt), 5*time.Second)
+}
+
+func TestExecInvokerURL(t *testing.T) {
+	for _, rawurl := range []string{
+		"exec://localhost/bin/true",
+		"exec://",
+		"http:///bin/true",
+	} {
+		_, err := NewExecInvoker(rawurl, time.Second)
+		assert.Error(t, err, rawurl)
+	}
+	ivk, err := NewExecInvoker("exec:///bin/echo?arg=a&arg=b", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "/bin/echo", ivk.path)
+	assert.Equal(t, []string{"a", "b"}, ivk.args)
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{limit: 5}
+	n, err := b.Write([]byte("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	n, err = b.Write([]byte("defgh"))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, "abcde...(truncated)", b.String())
+	assert.False(t, strings.Contains(b.String(), "f"))
+}
@@ -0,0 +1,347 @@
+package sqsd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FastCGIInvoker invokes worker process by FastCGI, such as PHP-FPM, without HTTP server in front of it.
+// Payload is sent as stdin, and headers of HTTPInvoker are sent as CGI params such as HTTP_X_AWS_SQSD_MSGID.
+// Status header of response is handled in the same way as HTTPInvoker.
+type FastCGIInvoker struct {
+	pool       *fastCGIPool
+	scriptPath string
+	timeout    time.Duration
+	policies   []StatusPolicy
+}
+
+type fastCGIInvokerParams struct {
+	policies []StatusPolicy
+	poolSize int
+}
+
+// FastCGIInvokerParameter sets parameter to FastCGIInvoker by functional option pattern.
+type FastCGIInvokerParameter func(*fastCGIInvokerParams)
+
+// FastCGIStatusPolicies sets policies of response status code to FastCGIInvoker.
+// These policies are matched in order, before DefaultStatusPolicies.
+func FastCGIStatusPolicies(policies ...StatusPolicy) FastCGIInvokerParameter {
+	return func(p *fastCGIInvokerParams) {
+		p.policies = append(p.policies, policies...)
+	}
+}
+
+// FastCGIPoolSize sets the number of idle connections which are kept for reuse.
+// it should be the same as parallel count of ConsumerBuilder. default is 1.
+func FastCGIPoolSize(size int) FastCGIInvokerParameter {
+	return func(p *fastCGIInvokerParams) {
+		p.poolSize = size
+	}
+}
+
+// NewFastCGIInvoker returns FastCGIInvoker instance.
+// rawurl is either of them:
+//
+//	fastcgi://127.0.0.1:9000/var/www/worker.php                       (TCP)
+//	fastcgi+unix:///run/php-fpm.sock?script=/var/www/worker.php       (unix socket)
+func NewFastCGIInvoker(rawurl string, dur time.Duration, params ...FastCGIInvokerParameter) (*FastCGIInvoker, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	var network, address, script string
+	switch u.Scheme {
+	case "fastcgi":
+		network, address, script = "tcp", u.Host, u.Path
+	case "fastcgi+unix":
+		network, address, script = "unix", u.Path, u.Query().Get("script")
+	default:
+		return nil, fmt.Errorf("unsupported scheme of FastCGI: %s", rawurl)
+	}
+	if address == "" || script == "" {
+		return nil, fmt.Errorf("address and script are required: %s", rawurl)
+	}
+	p := fastCGIInvokerParams{poolSize: 1}
+	for _, fn := range params {
+		fn(&p)
+	}
+	if p.poolSize < 1 {
+		p.poolSize = 1
+	}
+	return &FastCGIInvoker{
+		pool:       newFastCGIPool(network, address, p.poolSize),
+		scriptPath: script,
+		timeout:    dur,
+		policies:   append(p.policies, DefaultStatusPolicies...),
+	}, nil
+}
+
+// Invoke sends FastCGI request to worker process.
+func (ivk *FastCGIInvoker) Invoke(ctx context.Context, q Message) error {
+	if ivk.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ivk.timeout)
+		defer cancel()
+	}
+	params, err := ivk.params(q)
+	if err != nil {
+		return err
+	}
+	// idle connection may be closed by worker process, such as pm.max_requests of PHP-FPM,
+	// so request is sent again by new connection if nothing is received.
+	for reuse := true; ; reuse = false {
+		conn, reused, err := ivk.pool.get(ctx, reuse)
+		if err != nil {
+			return err
+		}
+		resp, received, err := conn.do(ctx, params, []byte(q.Payload))
+		if err != nil {
+			conn.Close()
+			if reused && !received && ctx.Err() == nil {
+				continue
+			}
+			return err
+		}
+		if resp.keepConn {
+			ivk.pool.put(conn)
+		} else {
+			conn.Close()
+		}
+		if len(resp.stderr) > 0 {
+			getLogger().Warn("FastCGI worker wrote to stderr", "message_id", q.ID, "stderr", string(resp.stderr))
+		}
+		return resp.handleStatus(ivk.policies)
+	}
+}
+
+func (ivk *FastCGIInvoker) params(q Message) (map[string]string, error) {
+	requestURI := ivk.scriptPath
+	var query string
+	if q.Path != "" {
+		ref, err := url.Parse(q.Path)
+		if err != nil {
+			return nil, err
+		}
+		requestURI, query = q.Path, ref.RawQuery
+	}
+	params := map[string]string{
+		"GATEWAY_INTERFACE": "CGI/1.1",
+		"SERVER_SOFTWARE":   "sqsd",
+		"SERVER_PROTOCOL":   "HTTP/1.1",
+		"SERVER_NAME":       "localhost",
+		"REMOTE_ADDR":       "127.0.0.1",
+		"REQUEST_METHOD":    http.MethodPost,
+		"SCRIPT_FILENAME":   ivk.scriptPath,
+		"SCRIPT_NAME":       ivk.scriptPath,
+		"REQUEST_URI":       requestURI,
+		"QUERY_STRING":      query,
+		"CONTENT_TYPE":      "application/json",
+		"CONTENT_LENGTH":    strconv.Itoa(len(q.Payload)),
+	}
+	h := make(http.Header)
+	setSqsdHeaders(h, q)
+	for name, values := range h {
+		key := "HTTP_" + strings.Map(func(r rune) rune {
+			if r == '-' || r == '.' {
+				return '_'
+			}
+			return r
+		}, strings.ToUpper(name))
+		params[key] = values[0]
+	}
+	return params, nil
+}
+
+// FastCGI record types and constants.
+const (
+	fcgiVersion       = 1
+	fcgiBeginRequest  = 1
+	fcgiEndRequest    = 3
+	fcgiParams        = 4
+	fcgiStdin         = 5
+	fcgiStdout        = 6
+	fcgiStderr        = 7
+	fcgiResponder     = 1
+	fcgiKeepConn      = 1
+	fcgiRequestID     = 1
+	fcgiMaxContentLen = 65535
+)
+
+type fastCGIPool struct {
+	network string
+	address string
+	idle    chan *fastCGIConn
+}
+
+func newFastCGIPool(network, address string, size int) *fastCGIPool {
+	return &fastCGIPool{
+		network: network,
+		address: address,
+		idle:    make(chan *fastCGIConn, size),
+	}
+}
+
+// get returns idle connection if reuse is true and it exists, or dials new connection.
+func (p *fastCGIPool) get(ctx context.Context, reuse bool) (*fastCGIConn, bool, error) {
+	if reuse {
+		select {
+		case conn := <-p.idle:
+			return conn, true, nil
+		default:
+		}
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, p.network, p.address)
+	if err != nil {
+		return nil, false, err
+	}
+	return &fastCGIConn{Conn: conn, r: bufio.NewReader(conn)}, false, nil
+}
+
+// put keeps connection for reuse, or closes it if pool is full.
+func (p *fastCGIPool) put(conn *fastCGIConn) {
+	select {
+	case p.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+type fastCGIConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+type fastCGIResponse struct {
+	stdout   []byte
+	stderr   []byte
+	keepConn bool
+}
+
+// do sends request and reads response.
+// received reports whether any record is received, to find out that connection is closed before request.
+func (c *fastCGIConn) do(ctx context.Context, params map[string]string, stdin []byte) (resp fastCGIResponse, received bool, err error) {
+	defer deadlineOnDone(ctx, c.SetDeadline)()
+
+	var buf bytes.Buffer
+	writeFastCGIRecord(&buf, fcgiBeginRequest, []byte{0, fcgiResponder, fcgiKeepConn, 0, 0, 0, 0, 0})
+	writeFastCGIStream(&buf, fcgiParams, encodeFastCGIParams(params))
+	writeFastCGIStream(&buf, fcgiStdin, stdin)
+	if _, err := c.Write(buf.Bytes()); err != nil {
+		return resp, false, ctxErr(ctx, err)
+	}
+
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(c.r, header[:]); err != nil {
+			return resp, received, ctxErr(ctx, err)
+		}
+		received = true
+		recType := header[1]
+		contentLen := binary.BigEndian.Uint16(header[4:6])
+		content := make([]byte, int(contentLen)+int(header[6]))
+		if _, err := io.ReadFull(c.r, content); err != nil {
+			return resp, received, ctxErr(ctx, err)
+		}
+		content = content[:contentLen]
+		if binary.BigEndian.Uint16(header[2:4]) != fcgiRequestID {
+			continue
+		}
+		switch recType {
+		case fcgiStdout:
+			resp.stdout = append(resp.stdout, content...)
+		case fcgiStderr:
+			resp.stderr = append(resp.stderr, content...)
+		case fcgiEndRequest:
+			if len(content) >= 5 && content[4] != 0 {
+				return resp, received, fmt.Errorf("FastCGI request is not completed: protocol status %d", content[4])
+			}
+			resp.keepConn = true
+			return resp, received, nil
+		}
+	}
+}
+
+// deadlineOnDone clears deadline by setDeadline, and sets it to now when ctx is done.
+// Deadline of ctx is not copied, because I/O may time out before ctx is done and then ctx.Err() is nil.
+// returned function stops it.
+func deadlineOnDone(ctx context.Context, setDeadline func(time.Time) error) func() bool {
+	_ = setDeadline(time.Time{})
+	return context.AfterFunc(ctx, func() {
+		_ = setDeadline(time.Now())
+	})
+}
+
+func ctxErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ctx.Err(), err)
+	}
+	return err
+}
+
+func writeFastCGIRecord(buf *bytes.Buffer, recType byte, content []byte) {
+	padding := (8 - len(content)%8) % 8
+	buf.Write([]byte{fcgiVersion, recType, 0, fcgiRequestID, byte(len(content) >> 8), byte(len(content)), byte(padding), 0})
+	buf.Write(content)
+	buf.Write(make([]byte, padding))
+}
+
+// writeFastCGIStream writes content as records, and terminates stream by empty record.
+func writeFastCGIStream(buf *bytes.Buffer, recType byte, content []byte) {
+	for len(content) > 0 {
+		n := len(content)
+		if n > fcgiMaxContentLen {
+			n = fcgiMaxContentLen
+		}
+		writeFastCGIRecord(buf, recType, content[:n])
+		content = content[n:]
+	}
+	writeFastCGIRecord(buf, recType, nil)
+}
+
+func encodeFastCGIParams(params map[string]string) []byte {
+	var buf bytes.Buffer
+	writeLen := func(n int) {
+		if n < 128 {
+			buf.WriteByte(byte(n))
+			return
+		}
+		_ = binary.Write(&buf, binary.BigEndian, uint32(n)|1<<31)
+	}
+	for name, value := range params {
+		writeLen(len(name))
+		writeLen(len(value))
+		buf.WriteString(name)
+		buf.WriteString(value)
+	}
+	return buf.Bytes()
+}
+
+// handleStatus parses CGI response, and converts its Status header to error by status policies.
+func (resp fastCGIResponse) handleStatus(policies []StatusPolicy) error {
+	r := bufio.NewReader(bytes.NewReader(resp.stdout))
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid FastCGI response: %w", err)
+	}
+	code := http.StatusOK
+	if status := header.Get("Status"); status != "" {
+		code, err = strconv.Atoi(strings.SplitN(status, " ", 2)[0])
+		if err != nil {
+			return fmt.Errorf("invalid Status header of FastCGI response: %q", status)
+		}
+	}
+	return handleStatus(policies, code, http.Header(header), r)
+}
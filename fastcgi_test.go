@@ -0,0 +1,146 @@
+package sqsd
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/fcgi"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countListener counts accepted connections, and keeps them to close them from server side.
+type countListener struct {
+	net.Listener
+	accepted atomic.Int32
+	mu       sync.Mutex
+	conns    []net.Conn
+}
+
+func (l *countListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+		l.mu.Lock()
+		l.conns = append(l.conns, conn)
+		l.mu.Unlock()
+	}
+	return conn, err
+}
+
+func (l *countListener) closeConns() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, conn := range l.conns {
+		conn.Close()
+	}
+	l.conns = nil
+}
+
+func startFastCGIServer(t *testing.T, network, address string, h http.HandlerFunc) *countListener {
+	l, err := net.Listen(network, address)
+	require.NoError(t, err)
+	cl := &countListener{Listener: l}
+	t.Cleanup(func() { cl.Close() })
+	go func() { _ = fcgi.Serve(cl, h) }()
+	return cl
+}
+
+func TestFastCGIInvoker(t *testing.T) {
+	type request struct {
+		env    map[string]string
+		header http.Header
+		body   string
+	}
+	reqs := make(chan request, 10)
+	l := startFastCGIServer(t, "tcp", "127.0.0.1:0", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		reqs <- request{env: fcgi.ProcessEnv(r), header: r.Header, body: string(b)}
+		switch r.Header.Get("X-Aws-Sqsd-Attr-Status") {
+		case "404":
+			w.WriteHeader(http.StatusNotFound)
+		case "429":
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "slow":
+			time.Sleep(time.Second)
+		}
+	})
+
+	ivk, err := NewFastCGIInvoker("fastcgi://"+l.Addr().String()+"/var/www/worker.php", 300*time.Millisecond,
+		FastCGIStatusPolicies(StatusPolicy{Min: 404, Max: 404, Action: StatusActionDeadLetter}),
+		FastCGIPoolSize(2))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	msg := Message{
+		ID:       "msg-1",
+		Payload:  `{"hello":"world"}`,
+		QueueURL: "http://localhost:9324/000000000000/my-queue",
+		Attributes: map[string]MessageAttribute{
+			"trace.id": {DataType: "String", StringValue: "abc"},
+		},
+	}
+	require.NoError(t, ivk.Invoke(ctx, msg))
+	req := <-reqs
+	assert.Equal(t, `{"hello":"world"}`, req.body)
+	assert.Equal(t, "msg-1", req.header.Get("X-Aws-Sqsd-Msgid"))
+	assert.Equal(t, "my-queue", req.header.Get("X-Aws-Sqsd-Queue"))
+	assert.Equal(t, "abc", req.header.Get("X-Aws-Sqsd-Attr-Trace-Id"))
+	assert.Equal(t, "/var/www/worker.php", req.env["SCRIPT_FILENAME"])
+
+	// connection is reused.
+	require.NoError(t, ivk.Invoke(ctx, Message{ID: "msg-2", Payload: "{}"}))
+	<-reqs
+	assert.Equal(t, int32(1), l.accepted.Load())
+
+	msg.Attributes = map[string]MessageAttribute{"Status": {DataType: "String", StringValue: "404"}}
+	err = ivk.Invoke(ctx, msg)
+	assert.ErrorIs(t, err, ErrDeadLetter)
+	<-reqs
+
+	msg.Attributes = map[string]MessageAttribute{"Status": {DataType: "String", StringValue: "429"}}
+	err = ivk.Invoke(ctx, msg)
+	var retryErr *RetryAfterError
+	if assert.ErrorAs(t, err, &retryErr) {
+		assert.Equal(t, 30*time.Second, retryErr.After)
+	}
+	<-reqs
+
+	// idle connection which is closed by server is replaced.
+	l.closeConns()
+	require.NoError(t, ivk.Invoke(ctx, Message{ID: "msg-3", Payload: "{}"}))
+	<-reqs
+	assert.Equal(t, int32(2), l.accepted.Load())
+
+	msg.Attributes = map[string]MessageAttribute{"Status": {DataType: "String", StringValue: "slow"}}
+	err = ivk.Invoke(ctx, msg)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestFastCGIInvokerUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "fpm.sock")
+	bodies := make(chan string, 1)
+	startFastCGIServer(t, "unix", sock, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- string(b)
+	})
+
+	_, err := NewFastCGIInvoker("fastcgi+unix://"+sock, time.Second)
+	assert.Error(t, err, "script is required")
+	_, err = NewFastCGIInvoker("http://localhost/", time.Second)
+	assert.Error(t, err)
+
+	ivk, err := NewFastCGIInvoker("fastcgi+unix://"+sock+"?script=/var/www/worker.php", time.Second)
+	require.NoError(t, err)
+	large := string(make([]byte, 100000))
+	require.NoError(t, ivk.Invoke(context.Background(), Message{ID: "msg-1", Payload: large}))
+	assert.Equal(t, large, <-bodies)
+}
@@ -0,0 +1,143 @@
+package sqsd
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fifoDetector is implemented by queueOperator which can tell whether message comes from FIFO queue.
+type fifoDetector interface {
+	isFIFO(msg Message) bool
+}
+
+// isFIFOQueue reports whether queueURL points FIFO queue by its suffix.
+func isFIFOQueue(queueURL string) bool {
+	return strings.HasSuffix(queueURL, ".fifo")
+}
+
+type groupKey struct {
+	queueURL string
+	groupID  string
+}
+
+func groupKeyOf(msg Message) groupKey {
+	return groupKey{
+		queueURL: msg.QueueURL,
+		groupID:  msg.MessageGroupID(),
+	}
+}
+
+// fifoGroups holds messages waiting for preceding message in the same group.
+// A group exists in map while any goroutine processes its messages.
+type fifoGroups struct {
+	mu      sync.Mutex
+	pending map[groupKey][]Message
+}
+
+func newFIFOGroups() *fifoGroups {
+	return &fifoGroups{
+		pending: make(map[groupKey][]Message),
+	}
+}
+
+// enqueue adds message to its group.
+// if the group is not active, it activates the group and returns true,
+// and then caller must process the message and following messages by next.
+func (g *fifoGroups) enqueue(key groupKey, msg Message) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if msgs, ok := g.pending[key]; ok {
+		g.pending[key] = append(msgs, msg)
+		return false
+	}
+	g.pending[key] = nil
+	return true
+}
+
+// next pops the next message of the group.
+// if no message is left, the group is deactivated.
+func (g *fifoGroups) next(key groupKey) (Message, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	msgs := g.pending[key]
+	if len(msgs) == 0 {
+		delete(g.pending, key)
+		return Message{}, false
+	}
+	g.pending[key] = msgs[1:]
+	return msgs[0], true
+}
+
+// drop deactivates the group and returns messages which are not processed yet.
+func (g *fifoGroups) drop(key groupKey) []Message {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	msgs := g.pending[key]
+	delete(g.pending, key)
+	return msgs
+}
+
+// takePending removes messages which wait for preceding message from all groups, and returns them.
+// Groups stay active until their running messages finish.
+func (g *fifoGroups) takePending() []Message {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var msgs []Message
+	for key, pending := range g.pending {
+		msgs = append(msgs, pending...)
+		g.pending[key] = nil
+	}
+	return msgs
+}
+
+// active returns MessageGroupIds of active groups.
+func (g *fifoGroups) active() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	groups := make([]string, 0, len(g.pending))
+	for key := range g.pending {
+		groups = append(groups, key.groupID)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+func (w *worker) isFIFO(msg Message, op queueOperator) bool {
+	d, ok := op.(fifoDetector)
+	return ok && d.isFIFO(msg)
+}
+
+// ActiveGroups returns MessageGroupIds which are processed or waiting in FIFO mode.
+func (w *worker) ActiveGroups(ctx context.Context) []string {
+	return w.groups.active()
+}
+
+// processGroup processes messages of the same MessageGroupId one by one in received order,
+// from msg which activates the group.
+// Only one goroutine processes a group at once, and dispatcher just appends following messages to it.
+// if processing fails, later messages in the group are not processed and given back to queue,
+// because SQS redelivers them after the failed message.
+func (w *worker) processGroup(ctx context.Context, msg Message, op queueOperator) {
+	key := groupKeyOf(msg)
+	for {
+		if !w.wrappedProcess(msg, op) || ctx.Err() != nil {
+			w.skipGroup(key, op)
+			return
+		}
+		var ok bool
+		if msg, ok = w.groups.next(key); !ok {
+			return
+		}
+	}
+}
+
+// skipGroup gives back messages in the group without processing them.
+func (w *worker) skipGroup(key groupKey, op queueOperator) {
+	for _, msg := range w.groups.drop(key) {
+		getLogger().Info("message is skipped because preceding message in the group is not completed.",
+			"message_id", msg.ID, "message_group_id", key.groupID)
+		w.giveBack(msg, op)
+	}
+}
@@ -0,0 +1,154 @@
+package sqsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFIFOGroups(t *testing.T) {
+	g := newFIFOGroups()
+	key := groupKey{queueURL: "q.fifo", groupID: "g1"}
+
+	assert.True(t, g.enqueue(key, Message{ID: "1"}))
+	assert.False(t, g.enqueue(key, Message{ID: "2"}))
+	assert.False(t, g.enqueue(key, Message{ID: "3"}))
+	assert.True(t, g.enqueue(groupKey{queueURL: "q.fifo", groupID: "g0"}, Message{ID: "4"}))
+	assert.Equal(t, []string{"g0", "g1"}, g.active())
+
+	msg, ok := g.next(key)
+	assert.True(t, ok)
+	assert.Equal(t, "2", msg.ID)
+
+	dropped := g.drop(key)
+	if assert.Len(t, dropped, 1) {
+		assert.Equal(t, "3", dropped[0].ID)
+	}
+	assert.Equal(t, []string{"g0"}, g.active())
+
+	_, ok = g.next(groupKey{queueURL: "q.fifo", groupID: "g0"})
+	assert.False(t, ok)
+	assert.Empty(t, g.active())
+}
+
+const fifoQueueURL = "https://sqs.ap-northeast-1.amazonaws.com/123456789012/test.fifo"
+
+func fifoMessage(id, group string) Message {
+	return Message{
+		ID:       id,
+		QueueURL: fifoQueueURL,
+		SystemAttributes: map[string]string{
+			AttributeMessageGroupID: group,
+		},
+	}
+}
+
+func TestWorkerFIFO(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var mu sync.Mutex
+	invoked := map[string][]string{}
+	running := map[string]int{}
+	var overlapped bool
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		group := q.MessageGroupID()
+		mu.Lock()
+		invoked[group] = append(invoked[group], q.ID)
+		running[group]++
+		if running[group] > 1 {
+			overlapped = true
+		}
+		mu.Unlock()
+		time.Sleep(30 * time.Millisecond)
+		mu.Lock()
+		running[group]--
+		mu.Unlock()
+		if q.ID == "b:2" {
+			return errors.New("failed")
+		}
+		return nil
+	}
+
+	op := &testQueueOperator{fifo: true}
+	broker := make(chan Message, 6)
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, op)
+	// slots are reserved by fetcher before sending messages to broker.
+	n, err := w.slots.acquire(ctx, fifoQueueURL, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	for _, msg := range []Message{
+		fifoMessage("a:1", "a"),
+		fifoMessage("b:1", "b"),
+		fifoMessage("a:2", "a"),
+		fifoMessage("b:2", "b"),
+		fifoMessage("a:3", "a"),
+		fifoMessage("b:3", "b"),
+	} {
+		broker <- msg
+	}
+	assert.Eventually(t, func() bool {
+		return len(w.CurrentWorkings(ctx)) == 2
+	}, 25*time.Millisecond, time.Millisecond, "groups are processed in parallel")
+	assert.Equal(t, []string{"a", "b"}, w.ActiveGroups(ctx))
+	if tasks := w.CurrentWorkings(ctx); assert.Len(t, tasks, 2) {
+		assert.ElementsMatch(t, []string{"a", "b"}, []string{tasks[0].MessageGroupId, tasks[1].MessageGroupId})
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	assert.Empty(t, w.ActiveGroups(ctx))
+	assert.Empty(t, w.CurrentWorkings(ctx))
+
+	mu.Lock()
+	assert.False(t, overlapped, "messages in the same group are never processed at once")
+	assert.Equal(t, []string{"a:1", "a:2", "a:3"}, invoked["a"])
+	// b:3 is not processed because b:2 failed.
+	assert.Equal(t, []string{"b:1", "b:2"}, invoked["b"])
+	mu.Unlock()
+
+	op.mu.Lock()
+	assert.ElementsMatch(t, []string{"a:1", "a:2", "a:3", "b:1"}, op.removed)
+	assert.Equal(t, []string{"b:2", "b:3"}, op.released)
+	// skipped message is visible immediately.
+	assert.Equal(t, []time.Duration{0}, op.timeouts)
+	op.mu.Unlock()
+
+	// all slots are freed.
+	n, err = w.slots.acquire(ctx, fifoQueueURL, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+}
+
+func TestWorkerFIFOKeepsReceivedOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var mu sync.Mutex
+	var invoked []string
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		mu.Lock()
+		invoked = append(invoked, q.ID)
+		mu.Unlock()
+		return nil
+	}
+
+	// many runners receive messages of the same group at once.
+	broker := make(chan Message, 8)
+	startWorker(ctx, testInvoker(testInvokerFn), broker, &testQueueOperator{fifo: true})
+	var expected []string
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("a:%d", i)
+		expected = append(expected, id)
+		broker <- fifoMessage(id, "a")
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, expected, invoked)
+}
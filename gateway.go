@@ -2,65 +2,107 @@ package sqsd
 
 import (
 	"context"
-	"errors"
 	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"github.com/aws/smithy-go"
-
 	"github.com/taiyoh/sqsd/v2/locker"
 	nooplocker "github.com/taiyoh/sqsd/v2/locker/noop"
 )
 
-// Gateway fetches and removes jobs from SQS.
+// Gateway fetches and removes jobs from queue backend.
 type Gateway struct {
 	queueURL        string
-	queue           *sqs.Client
+	backend         QueueBackend
 	locker          locker.QueueLocker
 	fetcherInterval time.Duration
 	parallel        int
-	input           *sqs.ReceiveMessageInput
+	input           ReceiveInput
+	deleter         *deleteBatcher
+	fifo            bool
+	weight          int
+	priority        int
+	maxConcurrency  int
+	invoker         Invoker
 }
 
 type gatewayParams struct {
-	fetcherInterval  time.Duration
-	waitTime         int32
-	timeout          int32
-	numberOfMessages int32
-	parallel         int
-	locker           locker.QueueLocker
+	fetcherInterval   time.Duration
+	waitTime          time.Duration
+	timeout           time.Duration
+	numberOfMessages  int32
+	parallel          int
+	locker            locker.QueueLocker
+	systemAttributes  []string
+	messageAttributes []string
+	deleteLinger      time.Duration
+	deleteMaxRetries  int
+	fifo              *bool
+	weight            int
+	priority          int
+	maxConcurrency    int
+	invoker           Invoker
 }
 
 // NewGateway returns Gateway object.
-func NewGateway(queue *sqs.Client, queueURL string, params ...GatewayParameter) *Gateway {
+func NewGateway(backend QueueBackend, queueURL string, params ...GatewayParameter) *Gateway {
 	param := gatewayParams{
-		fetcherInterval:  100 * time.Millisecond,
-		timeout:          30, // default Visibility Timeout
-		waitTime:         int32((20 * time.Second).Seconds()),
-		numberOfMessages: 10,
-		parallel:         1,
-		locker:           nooplocker.Get(),
+		fetcherInterval:   100 * time.Millisecond,
+		timeout:           30 * time.Second, // default Visibility Timeout
+		waitTime:          20 * time.Second,
+		numberOfMessages:  10,
+		parallel:          1,
+		weight:            1,
+		locker:            nooplocker.Get(),
+		systemAttributes:  []string{"All"},
+		messageAttributes: []string{"All"},
+		deleteLinger:      50 * time.Millisecond,
+		deleteMaxRetries:  16,
 	}
 	for _, fn := range params {
 		fn(&param)
 	}
+	fifo := isFIFOQueue(queueURL)
+	if param.fifo != nil {
+		fifo = *param.fifo
+	}
+	if fifo {
+		// FIFO mode requires MessageGroupId for ordering.
+		param.systemAttributes = withSystemAttribute(param.systemAttributes, AttributeMessageGroupID)
+	}
 
 	return &Gateway{
-		queue:           queue,
+		backend:         backend,
 		queueURL:        queueURL,
 		fetcherInterval: param.fetcherInterval,
-		locker:          nooplocker.Get(),
+		locker:          param.locker,
 		parallel:        param.parallel,
-		input: &sqs.ReceiveMessageInput{
-			QueueUrl:            &queueURL,
-			MaxNumberOfMessages: param.numberOfMessages,
-			WaitTimeSeconds:     param.waitTime,
-			VisibilityTimeout:   param.timeout,
+		input: ReceiveInput{
+			QueueURL:          queueURL,
+			MaxMessages:       int(param.numberOfMessages),
+			WaitTime:          param.waitTime,
+			VisibilityTimeout: param.timeout,
+			SystemAttributes:  param.systemAttributes,
+			MessageAttributes: param.messageAttributes,
 		},
+		deleter:        newDeleteBatcher(backend, queueURL, param.deleteLinger, param.deleteMaxRetries),
+		fifo:           fifo,
+		weight:         param.weight,
+		priority:       param.priority,
+		maxConcurrency: param.maxConcurrency,
+		invoker:        param.invoker,
 	}
 }
 
+// withSystemAttribute adds attr to system attribute names if it is not contained.
+func withSystemAttribute(names []string, attr string) []string {
+	for _, name := range names {
+		if name == "All" || name == attr {
+			return names
+		}
+	}
+	return append(names[:len(names):len(names)], attr)
+}
+
 // GatewayParameter sets parameter to fetcher by functional option pattern.
 type GatewayParameter func(*gatewayParams)
 
@@ -74,7 +116,7 @@ func FetchInterval(d time.Duration) GatewayParameter {
 // FetcherWaitTime sets WaitTimeSecond of receiving message request.
 func FetcherWaitTime(d time.Duration) GatewayParameter {
 	return func(g *gatewayParams) {
-		g.waitTime = int32(d.Seconds())
+		g.waitTime = d
 	}
 }
 
@@ -85,7 +127,7 @@ func FetcherVisibilityTimeout(d time.Duration) GatewayParameter {
 		d = max
 	}
 	return func(g *gatewayParams) {
-		g.timeout = int32(d.Seconds())
+		g.timeout = d
 	}
 }
 
@@ -112,6 +154,92 @@ func FetcherMaxMessages(n int32) GatewayParameter {
 	}
 }
 
+// FetcherSystemAttributes sets names of message system attributes to be received.
+// Fetcher's default value is "All".
+// if no names are supplied, fetcher receives no system attributes.
+func FetcherSystemAttributes(names ...string) GatewayParameter {
+	return func(g *gatewayParams) {
+		g.systemAttributes = names
+	}
+}
+
+// FetcherMessageAttributes sets names of user-specified message attributes to be received.
+// Fetcher's default value is "All", and "prefix.*" style name is also accepted.
+// if no names are supplied, fetcher receives no message attributes.
+func FetcherMessageAttributes(names ...string) GatewayParameter {
+	return func(g *gatewayParams) {
+		g.messageAttributes = names
+	}
+}
+
+// RemoverBatchLinger sets duration to wait for collecting messages to delete in a batch.
+// Batch is sent by DeleteMessageBatch when it has 10 messages or this duration passes.
+// Remover's default value is 50ms, and if d is 0, every message is deleted immediately.
+func RemoverBatchLinger(d time.Duration) GatewayParameter {
+	return func(g *gatewayParams) {
+		g.deleteLinger = d
+	}
+}
+
+// RemoverMaxRetries sets max attempts of deleting each message.
+// Remover's default value is 16.
+func RemoverMaxRetries(n int) GatewayParameter {
+	if n < 1 {
+		n = 1
+	}
+	return func(g *gatewayParams) {
+		g.deleteMaxRetries = n
+	}
+}
+
+// FetcherFIFO sets FIFO mode of queue explicitly.
+// In FIFO mode, messages which have the same MessageGroupId are processed one by one in received order.
+// By default, FIFO mode is enabled if queue URL has ".fifo" suffix.
+func FetcherFIFO(enabled bool) GatewayParameter {
+	return func(g *gatewayParams) {
+		g.fifo = &enabled
+	}
+}
+
+// GatewayWeight sets weight of queue for sharing worker slots with other queues.
+// Queues of the same priority receive messages in proportion to their weights.
+// Gateway's default value is 1.
+func GatewayWeight(n int) GatewayParameter {
+	if n < 1 {
+		n = 1
+	}
+	return func(g *gatewayParams) {
+		g.weight = n
+	}
+}
+
+// GatewayPriority sets priority of queue.
+// While queue of higher priority is waiting for free worker slots, queues of lower priority do not receive messages.
+// Gateway's default value is 0.
+func GatewayPriority(n int) GatewayParameter {
+	return func(g *gatewayParams) {
+		g.priority = n
+	}
+}
+
+// GatewayMaxConcurrency sets the upper limit of messages of queue which are processed at once.
+// Gateway's default value is 0, which means the limit is only the parallelism of consumer.
+func GatewayMaxConcurrency(n int) GatewayParameter {
+	if n < 0 {
+		n = 0
+	}
+	return func(g *gatewayParams) {
+		g.maxConcurrency = n
+	}
+}
+
+// GatewayInvoker sets invoker for messages of queue instead of the invoker of consumer.
+func GatewayInvoker(ivk Invoker) GatewayParameter {
+	return func(g *gatewayParams) {
+		g.invoker = ivk
+	}
+}
+
 // FetcherParalles sets pallalel count of fetching process to SQS.
 func FetchParallel(n int) GatewayParameter {
 	return func(g *gatewayParams) {
@@ -119,73 +247,122 @@ func FetchParallel(n int) GatewayParameter {
 	}
 }
 
-func (f Gateway) start(ctx context.Context, broker chan Message) {
+// slotAcquirer reserves worker slots for fetched messages.
+type slotAcquirer interface {
+	wait(ctx context.Context, max int) (int, error)
+	tryAcquire(max int) int
+	release(n int)
+}
+
+func (f Gateway) start(ctx context.Context, broker chan Message, acq slotAcquirer) {
 	var wg sync.WaitGroup
 	wg.Add(f.parallel)
 	for i := 0; i < f.parallel; i++ {
-		go f.runForFetch(ctx, &wg, broker, f.input)
+		go f.runForFetch(ctx, &wg, broker, acq)
 	}
 	wg.Wait()
 
-	close(broker)
+	f.deleter.drain()
 }
 
-func (f *Gateway) runForFetch(ctx context.Context, wg *sync.WaitGroup, broker chan Message, input *sqs.ReceiveMessageInput) {
+func (f *Gateway) runForFetch(ctx context.Context, wg *sync.WaitGroup, broker chan Message, acq slotAcquirer) {
 	defer wg.Done()
 	logger := getLogger()
 	for {
-		if err := ctx.Err(); err != nil {
+		// fetcher pauses until any worker slots are free.
+		n, err := acq.wait(ctx, f.input.MaxMessages)
+		if err != nil {
 			return
 		}
-		out, err := f.queue.ReceiveMessage(ctx, input)
+		input := f.input
+		input.MaxMessages = n
+		msgs, err := f.backend.Receive(ctx, input)
 		if err != nil {
-			var apiErr *smithy.CanceledError
-			if errors.As(err, &apiErr) {
+			if ctx.Err() != nil {
 				return
 			}
-			logger.Error("failed to fetch from SQS", "error", err)
+			logger.Error("failed to fetch from queue", "error", err)
+			time.Sleep(f.fetcherInterval)
+			continue
+		}
+		// slots are reserved after receiving, so that long polling does not hold them.
+		// messages over free slots, which are taken by other queues while receiving, are given back.
+		// all messages are given back while consumer is paused, because no slots are reserved.
+		n = acq.tryAcquire(len(msgs))
+		f.giveBack(msgs[n:])
+		msgs = msgs[:n]
+		if ctx.Err() != nil {
+			// messages received at shutdown are given back without locking.
+			f.giveBack(msgs)
+			acq.release(n)
+			return
 		}
 		receivedAt := time.Now().UTC()
-		for _, msg := range out.Messages {
-			if err := f.locker.Lock(ctx, *msg.MessageId); err != nil {
+		var sent int
+		for _, msg := range msgs {
+			if err := f.locker.Lock(ctx, msg.ID); err != nil {
 				if err == locker.ErrQueueExists {
-					logger.Warn("received message is duplicated", "message_id", *msg.MessageId)
+					logger.Warn("received message is duplicated", "message_id", msg.ID)
 				} else {
 					logger.Error("failed to lock", "error", err)
 				}
 				continue
 			}
-			broker <- Message{
-				ID:         *msg.MessageId,
-				Payload:    *msg.Body,
-				Receipt:    *msg.ReceiptHandle,
-				ReceivedAt: receivedAt,
-			}
+			msg.ReceivedAt = receivedAt
+			msg.VisibilityTimeout = f.input.VisibilityTimeout
+			msg.QueueURL = f.queueURL
+			broker <- msg
+			sent++
 		}
-		logger.Debug("caught messages.", "length", len(out.Messages))
+		// slots which are not used by messages are freed.
+		acq.release(n - sent)
+		logger.Debug("caught messages.", "length", len(msgs))
 		time.Sleep(f.fetcherInterval)
 	}
 }
 
-// Remove sends delete-message to SQS.
-func (g *Gateway) remove(ctx context.Context, msg Message) (err error) {
-	// in some tests, queue object is empty for nothing to do it.
-	if g.queue == nil {
-		return nil
+// giveBack makes received messages visible again immediately.
+func (g *Gateway) giveBack(msgs []Message) {
+	ctx := context.Background()
+	for _, msg := range msgs {
+		if err := g.changeVisibility(ctx, msg, 0); err != nil {
+			getLogger().Warn("failed to give back message", "message_id", msg.ID, "error", err)
+		}
 	}
+}
+
+// isFIFO returns true if messages from this gateway should be processed in FIFO mode.
+func (g *Gateway) isFIFO(msg Message) bool {
+	return g.fifo
+}
+
+// changeVisibility changes VisibilityTimeout of message.
+func (g *Gateway) changeVisibility(ctx context.Context, msg Message, timeout time.Duration) error {
+	return g.backend.ChangeVisibility(ctx, g.queueURL, msg.Receipt, timeout)
+}
+
+// Remove deletes message from queue.
+// Message stays locked as done only if it is removed successfully, otherwise its lock is released.
+func (g *Gateway) remove(ctx context.Context, msg Message) error {
 	logger := getLogger()
-	for i := 0; i < 16; i++ {
-		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-		_, err = g.queue.DeleteMessage(ctx, &sqs.DeleteMessageInput{
-			QueueUrl:      &g.queueURL,
-			ReceiptHandle: &msg.Receipt,
-		})
-		cancel()
-		if err == nil {
-			logger.Debug("succeeded to remove message", "message_id", msg.ID)
-			return nil
+	if err := g.deleter.delete(msg); err != nil {
+		if err := g.release(ctx, msg); err != nil {
+			logger.Error("failed to release lock", "message_id", msg.ID, "error", err)
 		}
-		time.Sleep(time.Second)
+		return err
+	}
+	if err := g.locker.Done(ctx, msg.ID); err != nil {
+		logger.Error("failed to mark message as done", "message_id", msg.ID, "error", err)
 	}
-	return err
+	return nil
+}
+
+// inflightDeletes returns the number of messages which are waiting for deletion.
+func (g *Gateway) inflightDeletes() int64 {
+	return g.deleter.inflight.Load()
+}
+
+// release releases lock of message, so that redelivered message can be processed again.
+func (g *Gateway) release(ctx context.Context, msg Message) error {
+	return g.locker.Release(ctx, msg.ID)
 }
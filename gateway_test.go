@@ -8,33 +8,29 @@ import (
 	"testing"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestFetcherAndRemover(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
-	resourceName := fmt.Sprintf("fetcher-and-remover-%d", time.Now().UnixNano())
-	queue := sqs.NewFromConfig(awsConf, sqsEndpoint)
-	queueURL, err := setupSQS(t, queue, resourceName)
-	if err != nil {
-		panic(err)
-	}
-
+	queueURL := "http://localhost:9324/queue/fetcher-and-remover"
+	backend := newTestQueueBackend()
 	for i := 0; i < 20; i++ {
-		body := fmt.Sprintf(`{"foo":"bar","hoge":100,"index":%d}`, i)
-		_, err := queue.SendMessage(context.Background(), &sqs.SendMessageInput{
-			QueueUrl:    &queueURL,
-			MessageBody: &body,
+		backend.push(Message{
+			ID:      fmt.Sprintf("id:%d", i),
+			Payload: fmt.Sprintf(`{"foo":"bar","hoge":100,"index":%d}`, i),
 		})
-		assert.NoError(t, err)
 	}
 
 	broker := make(chan Message, 3)
 
-	f := NewGateway(queue, queueURL, FetchParallel(5), FetchInterval(50*time.Millisecond))
-	go f.start(ctx, broker)
+	f := NewGateway(backend, queueURL, FetchParallel(5), FetchInterval(50*time.Millisecond))
+	sl := newSlots(cap(broker)).forTenant(queueURL)
+	go func() {
+		f.start(ctx, broker, sl)
+		close(broker)
+	}()
 
 	var removed int32
 	var wg sync.WaitGroup
@@ -46,11 +42,14 @@ func TestFetcherAndRemover(t *testing.T) {
 			if assert.NoError(t, f.remove(ctx, msg)) {
 				atomic.AddInt32(&removed, 1)
 			}
+			sl.release(1)
 		}
 	}()
 
 	var sent int
 	for msg := range broker {
+		assert.Equal(t, queueURL, msg.QueueURL)
+		assert.False(t, msg.ReceivedAt.IsZero())
 		ch <- msg
 		sent++
 		if sent == 20 {
@@ -62,4 +61,128 @@ func TestFetcherAndRemover(t *testing.T) {
 	wg.Wait()
 
 	assert.Equal(t, int32(20), removed)
+	backend.mu.Lock()
+	assert.Len(t, backend.deleted, 20)
+	backend.mu.Unlock()
+}
+
+// idleQueueBackend emulates long polling of empty queue until ctx is done.
+type idleQueueBackend struct {
+	*testQueueBackend
+}
+
+func (b idleQueueBackend) Receive(ctx context.Context, in ReceiveInput) ([]Message, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGatewaysShareSlots(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	busyURL := "http://localhost:9324/queue/busy"
+	backend := newTestQueueBackend()
+	for i := 0; i < 5; i++ {
+		backend.push(Message{ID: fmt.Sprintf("id:%d", i)})
+	}
+	sl := newSlots(1)
+	broker := make(chan Message, 1)
+	busy := NewGateway(backend, busyURL, FetchInterval(time.Millisecond))
+	idle := NewGateway(idleQueueBackend{newTestQueueBackend()}, "http://localhost:9324/queue/idle")
+	go busy.start(ctx, broker, sl.forTenant(busyURL))
+	go idle.start(ctx, broker, sl.forTenant(idle.queueURL))
+
+	for i := 0; i < 5; i++ {
+		select {
+		case msg := <-broker:
+			assert.Equal(t, busyURL, msg.QueueURL)
+			sl.release(busyURL, 1)
+		case <-time.After(time.Second):
+			t.Fatal("idle queue must not hold slots while long polling")
+		}
+	}
+}
+
+func TestGatewayGivesBackMessagesOverSlots(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queueURL := "http://localhost:9324/queue/give-back"
+	backend := newTestQueueBackend()
+	backend.push(Message{ID: "id:1"}, Message{ID: "id:2"}, Message{ID: "id:3"})
+	sl := newSlots(3)
+	// slots are taken by other queue after fetcher waits for them.
+	acq := takenSlots{tenantSlots: sl.forTenant(queueURL), once: new(sync.Once)}
+	broker := make(chan Message, 3)
+	g := NewGateway(backend, queueURL)
+	go g.start(ctx, broker, acq)
+
+	msg := <-broker
+	assert.Equal(t, "id:1", msg.ID)
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, broker)
+	backend.mu.Lock()
+	assert.Equal(t, map[string]time.Duration{"receipt-2": 0, "receipt-3": 0}, backend.changed)
+	backend.mu.Unlock()
+}
+
+// takenSlots lets other queue take slots except one while receiving, only once.
+type takenSlots struct {
+	tenantSlots
+	once *sync.Once
+}
+
+func (t takenSlots) tryAcquire(max int) int {
+	t.once.Do(func() {
+		t.slots.tryAcquire("other", t.slots.capacity()-1)
+	})
+	return t.tenantSlots.tryAcquire(max)
+}
+
+func TestGatewayReceiveInput(t *testing.T) {
+	queueURL := "http://localhost:9324/000000000000/my-queue"
+	g := NewGateway(nil, queueURL,
+		FetcherSystemAttributes(AttributeApproximateReceiveCount),
+		FetcherMessageAttributes("Kind"),
+		FetcherWaitTime(5*time.Second),
+		FetcherVisibilityTimeout(time.Minute))
+	assert.Equal(t, ReceiveInput{
+		QueueURL:          queueURL,
+		MaxMessages:       10,
+		WaitTime:          5 * time.Second,
+		VisibilityTimeout: time.Minute,
+		SystemAttributes:  []string{"ApproximateReceiveCount"},
+		MessageAttributes: []string{"Kind"},
+	}, g.input)
+}
+
+func TestWithSystemAttribute(t *testing.T) {
+	assert.Equal(t, []string{"All"}, withSystemAttribute([]string{"All"}, AttributeApproximateReceiveCount))
+	assert.Equal(t, []string{AttributeApproximateReceiveCount}, withSystemAttribute(nil, AttributeApproximateReceiveCount))
+	names := []string{AttributeSenderID}
+	assert.Equal(t, []string{AttributeSenderID, AttributeApproximateReceiveCount}, withSystemAttribute(names, AttributeApproximateReceiveCount))
+	assert.Equal(t, []string{AttributeSenderID}, names, "given names are not modified")
+	assert.Equal(t, names, withSystemAttribute(names, AttributeSenderID))
+}
+
+func TestGatewayFIFO(t *testing.T) {
+	g := NewGateway(nil, "https://sqs.ap-northeast-1.amazonaws.com/123456789012/test.fifo")
+	assert.True(t, g.isFIFO(Message{}))
+	assert.Equal(t, []string{"All"}, g.input.SystemAttributes)
+
+	g = NewGateway(nil, "https://sqs.ap-northeast-1.amazonaws.com/123456789012/test.fifo",
+		FetcherSystemAttributes(AttributeApproximateReceiveCount))
+	assert.Equal(t, []string{
+		AttributeApproximateReceiveCount,
+		AttributeMessageGroupID,
+	}, g.input.SystemAttributes, "MessageGroupId is always received in FIFO mode")
+
+	g = NewGateway(nil, "https://sqs.ap-northeast-1.amazonaws.com/123456789012/test.fifo", FetcherFIFO(false))
+	assert.False(t, g.isFIFO(Message{}))
+
+	g = NewGateway(nil, "http://localhost:9324/queue/test", FetcherFIFO(true))
+	assert.True(t, g.isFIFO(Message{}))
+
+	g = NewGateway(nil, "http://localhost:9324/queue/test")
+	assert.False(t, g.isFIFO(Message{}))
 }
@@ -0,0 +1,155 @@
+package sqsd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCInvoker invokes worker process by Handle RPC of JobHandler service.
+// JobResult tells how message is handled, so that worker process can give retry duration and reason of dead-letter.
+type GRPCInvoker struct {
+	conn    *grpc.ClientConn
+	client  JobHandlerClient
+	timeout time.Duration
+}
+
+type grpcInvokerParams struct {
+	dialOptions []grpc.DialOption
+}
+
+// GRPCInvokerParameter sets parameter to GRPCInvoker by functional option pattern.
+type GRPCInvokerParameter func(*grpcInvokerParams)
+
+// GRPCDialOptions adds options to connect to worker process, such as transport credentials.
+// By default, connection is insecure.
+func GRPCDialOptions(opts ...grpc.DialOption) GRPCInvokerParameter {
+	return func(p *grpcInvokerParams) {
+		p.dialOptions = append(p.dialOptions, opts...)
+	}
+}
+
+// NewGRPCInvoker returns GRPCInvoker instance.
+// rawurl is either of them:
+//
+//	grpc://127.0.0.1:50051             (TCP)
+//	grpc+unix:///run/worker.sock       (unix socket)
+func NewGRPCInvoker(rawurl string, dur time.Duration, params ...GRPCInvokerParameter) (*GRPCInvoker, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	var target string
+	switch u.Scheme {
+	case "grpc":
+		if u.Host == "" {
+			return nil, fmt.Errorf("address is required: %s", rawurl)
+		}
+		target = u.Host
+	case "grpc+unix":
+		if u.Path == "" {
+			return nil, fmt.Errorf("socket path is required: %s", rawurl)
+		}
+		target = "unix://" + u.Path
+	default:
+		return nil, fmt.Errorf("unsupported scheme of gRPC: %s", rawurl)
+	}
+	p := grpcInvokerParams{
+		dialOptions: []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+	}
+	for _, fn := range params {
+		fn(&p)
+	}
+	conn, err := grpc.Dial(target, p.dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCInvoker{
+		conn:    conn,
+		client:  NewJobHandlerClient(conn),
+		timeout: dur,
+	}, nil
+}
+
+// JobError is the reason of JobResult which is not JOB_ACTION_DELETE.
+type JobError struct {
+	Action JobAction
+	Reason string
+}
+
+func (e *JobError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("job is handled as %s", e.Action)
+	}
+	return fmt.Sprintf("job is handled as %s: %s", e.Action, e.Reason)
+}
+
+// Invoke calls Handle RPC with message, and converts JobResult to error.
+func (ivk *GRPCInvoker) Invoke(ctx context.Context, q Message) error {
+	if ivk.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ivk.timeout)
+		defer cancel()
+	}
+	result, err := ivk.client.Handle(ctx, newJob(q))
+	if err != nil {
+		return err
+	}
+	return result.wrap()
+}
+
+// Close closes connection to worker process.
+func (ivk *GRPCInvoker) Close() error {
+	return ivk.conn.Close()
+}
+
+func newJob(q Message) *Job {
+	job := &Job{
+		Id:               q.ID,
+		Payload:          q.Payload,
+		ReceiveCount:     int32(q.ReceiveCount()),
+		QueueUrl:         q.QueueURL,
+		SystemAttributes: q.SystemAttributes,
+		TaskName:         q.TaskName,
+	}
+	if len(q.Attributes) > 0 {
+		job.Attributes = make(map[string]*JobAttribute, len(q.Attributes))
+		for name, attr := range q.Attributes {
+			job.Attributes[name] = &JobAttribute{
+				DataType:    attr.DataType,
+				StringValue: attr.StringValue,
+				BinaryValue: attr.BinaryValue,
+			}
+		}
+	}
+	if !q.ScheduledAt.IsZero() {
+		job.ScheduledAt = timestamppb.New(q.ScheduledAt)
+	}
+	return job
+}
+
+// wrap converts JobResult to the error which tells worker how to handle message, in the same way as StatusPolicy.
+// JOB_ACTION_UNSPECIFIED is retried as failure.
+func (r *JobResult) wrap() error {
+	policy := StatusPolicy{Delay: r.GetRetryAfter().AsDuration()}
+	switch r.GetAction() {
+	case JobAction_JOB_ACTION_UNSPECIFIED:
+		return &JobError{Action: r.GetAction(), Reason: "action of JobResult is not specified"}
+	case JobAction_JOB_ACTION_DELETE:
+		return nil
+	case JobAction_JOB_ACTION_RETAIN:
+		policy.Action = StatusActionRetain
+	case JobAction_JOB_ACTION_RETRY:
+		policy.Action = StatusActionRetry
+	case JobAction_JOB_ACTION_DEAD_LETTER:
+		policy.Action = StatusActionDeadLetter
+	default:
+		return fmt.Errorf("unknown action of JobResult: %d", r.GetAction())
+	}
+	return policy.wrap(&JobError{Action: r.GetAction(), Reason: r.GetReason()})
+}
@@ -0,0 +1,150 @@
+package sqsd
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type testJobHandler struct {
+	UnimplementedJobHandlerServer
+	jobs chan *Job
+}
+
+func (h *testJobHandler) Handle(ctx context.Context, job *Job) (*JobResult, error) {
+	h.jobs <- job
+	switch job.GetAttributes()["action"].GetStringValue() {
+	case "retain":
+		return &JobResult{Action: JobAction_JOB_ACTION_RETAIN}, nil
+	case "retry":
+		return &JobResult{Action: JobAction_JOB_ACTION_RETRY, RetryAfter: durationpb.New(30 * time.Second), Reason: "busy"}, nil
+	case "dead-letter":
+		return &JobResult{Action: JobAction_JOB_ACTION_DEAD_LETTER, Reason: "broken payload"}, nil
+	case "error":
+		return nil, status.Error(codes.Internal, "panic")
+	case "slow":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	case "unspecified":
+		return &JobResult{}, nil
+	}
+	return &JobResult{Action: JobAction_JOB_ACTION_DELETE}, nil
+}
+
+func startJobHandler(t *testing.T, network, address string) (*testJobHandler, net.Addr) {
+	l, err := net.Listen(network, address)
+	require.NoError(t, err)
+	h := &testJobHandler{jobs: make(chan *Job, 10)}
+	srv := grpc.NewServer()
+	RegisterJobHandlerServer(srv, h)
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(srv.Stop)
+	return h, l.Addr()
+}
+
+func TestGRPCInvoker(t *testing.T) {
+	h, addr := startJobHandler(t, "tcp", "127.0.0.1:0")
+	ivk, err := NewGRPCInvoker("grpc://"+addr.String(), 300*time.Millisecond)
+	require.NoError(t, err)
+	defer ivk.Close()
+
+	ctx := context.Background()
+	msg := Message{
+		ID:       "msg-1",
+		Payload:  `{"hello":"world"}`,
+		QueueURL: "http://localhost:9324/000000000000/my-queue",
+		SystemAttributes: map[string]string{
+			AttributeApproximateReceiveCount: "2",
+		},
+		Attributes: map[string]MessageAttribute{
+			"trace.id": {DataType: "String", StringValue: "abc"},
+			"data":     {DataType: "Binary", BinaryValue: []byte{1, 2}},
+		},
+	}
+	require.NoError(t, ivk.Invoke(ctx, msg))
+	job := <-h.jobs
+	assert.Equal(t, "msg-1", job.GetId())
+	assert.Equal(t, `{"hello":"world"}`, job.GetPayload())
+	assert.Equal(t, int32(2), job.GetReceiveCount())
+	assert.Equal(t, msg.QueueURL, job.GetQueueUrl())
+	assert.Equal(t, "abc", job.GetAttributes()["trace.id"].GetStringValue())
+	assert.Equal(t, []byte{1, 2}, job.GetAttributes()["data"].GetBinaryValue())
+	assert.Equal(t, "2", job.GetSystemAttributes()[AttributeApproximateReceiveCount])
+
+	withAction := func(action string) Message {
+		m := msg
+		m.Attributes = map[string]MessageAttribute{"action": {DataType: "String", StringValue: action}}
+		return m
+	}
+
+	err = ivk.Invoke(ctx, withAction("retain"))
+	assert.ErrorIs(t, err, ErrRetainMessage)
+
+	err = ivk.Invoke(ctx, withAction("retry"))
+	var retryErr *RetryAfterError
+	if assert.ErrorAs(t, err, &retryErr) {
+		assert.Equal(t, 30*time.Second, retryErr.After)
+	}
+
+	err = ivk.Invoke(ctx, withAction("dead-letter"))
+	assert.ErrorIs(t, err, ErrDeadLetter)
+	var jobErr *JobError
+	if assert.ErrorAs(t, err, &jobErr) {
+		assert.Equal(t, "broken payload", jobErr.Reason)
+	}
+
+	// message is not deleted when worker forgets to set action.
+	err = ivk.Invoke(ctx, withAction("unspecified"))
+	if assert.ErrorAs(t, err, &jobErr) {
+		assert.Equal(t, JobAction_JOB_ACTION_UNSPECIFIED, jobErr.Action)
+	}
+	assert.NotErrorIs(t, err, ErrDeadLetter)
+
+	err = ivk.Invoke(ctx, withAction("error"))
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotErrorIs(t, err, ErrDeadLetter)
+
+	err = ivk.Invoke(ctx, withAction("slow"))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestGRPCInvokerUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "worker.sock")
+	h, _ := startJobHandler(t, "unix", sock)
+
+	for _, rawurl := range []string{
+		"grpc://",
+		"grpc+unix://",
+		"http://localhost:50051",
+	} {
+		_, err := NewGRPCInvoker(rawurl, time.Second)
+		assert.Error(t, err, rawurl)
+	}
+
+	ivk, err := NewGRPCInvoker("grpc+unix://"+sock, time.Second)
+	require.NoError(t, err)
+	defer ivk.Close()
+	require.NoError(t, ivk.Invoke(context.Background(), Message{ID: "msg-1", TaskName: "cleanup", ScheduledAt: time.Unix(1700000000, 0)}))
+	job := <-h.jobs
+	assert.Equal(t, "cleanup", job.GetTaskName())
+	assert.Equal(t, int64(1700000000), job.GetScheduledAt().GetSeconds())
+}
+
+func TestJobResultWrap(t *testing.T) {
+	assert.NoError(t, (&JobResult{Action: JobAction_JOB_ACTION_DELETE}).wrap())
+	assert.EqualError(t, (&JobResult{}).wrap(), "job is handled as JOB_ACTION_UNSPECIFIED: action of JobResult is not specified")
+	// retry without duration follows RetryPolicy of consumer.
+	err := (&JobResult{Action: JobAction_JOB_ACTION_RETRY, Reason: "busy"}).wrap()
+	assert.Equal(t, &JobError{Action: JobAction_JOB_ACTION_RETRY, Reason: "busy"}, err)
+	assert.EqualError(t, err, "job is handled as JOB_ACTION_RETRY: busy")
+	assert.Error(t, (&JobResult{Action: JobAction(100)}).wrap())
+}
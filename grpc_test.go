@@ -47,4 +47,8 @@ func TestGRPC(t *testing.T) {
 	resp, err := client.CurrentWorkings(context.Background(), &CurrentWorkingsRequest{})
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
+
+	stats, err := client.Stats(context.Background(), &StatsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), stats.GetInflightDeletes())
 }
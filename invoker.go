@@ -3,63 +3,136 @@ package sqsd
 import (
 	"bytes"
 	"context"
-	"fmt"
+	"encoding/base64"
 	"io"
 	"net/http"
 	"net/url"
+	"path"
+	"strconv"
 	"time"
 )
 
 // Invoker invokes worker process by any way.
+// Invoker which wraps other invokers should implement Unwrap() Invoker, or Unwrap() []Invoker for several invokers,
+// so that System finds ProcessPoolInvoker in them to start its worker processes and report their states.
 type Invoker interface {
 	Invoke(context.Context, Message) error
 }
 
 // HTTPInvoker invokes worker process by HTTP POST request.
 type HTTPInvoker struct {
-	url string
-	cli *http.Client
+	url      *url.URL
+	cli      *http.Client
+	policies []StatusPolicy
+}
+
+type httpInvokerParams struct {
+	policies []StatusPolicy
+}
+
+// HTTPInvokerParameter sets parameter to HTTPInvoker by functional option pattern.
+type HTTPInvokerParameter func(*httpInvokerParams)
+
+// HTTPStatusPolicies sets policies of response status code to HTTPInvoker.
+// These policies are matched in order, before DefaultStatusPolicies.
+func HTTPStatusPolicies(policies ...StatusPolicy) HTTPInvokerParameter {
+	return func(p *httpInvokerParams) {
+		p.policies = append(p.policies, policies...)
+	}
 }
 
 // NewHTTPInvoker returns HTTPInvoker instance.
-func NewHTTPInvoker(rawurl string, dur time.Duration) (*HTTPInvoker, error) {
-	if _, err := url.Parse(rawurl); err != nil {
+func NewHTTPInvoker(rawurl string, dur time.Duration, params ...HTTPInvokerParameter) (*HTTPInvoker, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
 		return nil, err
 	}
+	var param httpInvokerParams
+	for _, fn := range params {
+		fn(&param)
+	}
 	return &HTTPInvoker{
-		url: rawurl,
+		url: u,
 		cli: &http.Client{
 			Timeout: dur,
 		},
+		policies: append(param.policies, DefaultStatusPolicies...),
 	}, nil
 }
 
 // Invoke run http request to assigned URL.
 func (ivk *HTTPInvoker) Invoke(ctx context.Context, q Message) error {
 	buf := bytes.NewBuffer([]byte(q.Payload))
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ivk.url, buf)
+	u := ivk.url
+	if q.Path != "" {
+		ref, err := url.Parse(q.Path)
+		if err != nil {
+			return err
+		}
+		u = u.ResolveReference(ref)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), buf)
 	if err != nil {
 		return err
 	}
 	req.Header.Add("Content-Type", "application/json")
+	setSqsdHeaders(req.Header, q)
 	resp, err := ivk.cli.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
-	logger := getLogger()
-	switch s := resp.StatusCode; {
-	case s >= http.StatusInternalServerError:
-		b, _ := io.ReadAll(resp.Body)
-		logger.Info("response is failure status",
-			"status_code", resp.StatusCode,
-			"body", string(b))
-		return fmt.Errorf("failure response: %d", s)
-	case s >= http.StatusMultipleChoices:
-		b, _ := io.ReadAll(resp.Body)
-		logger.Info("response is not ok status",
-			"status_code", resp.StatusCode,
-			"body", string(b))
+	return handleStatus(ivk.policies, resp.StatusCode, resp.Header, resp.Body)
+}
+
+// handleStatus converts response status to error by status policies.
+func handleStatus(policies []StatusPolicy, code int, header http.Header, body io.Reader) error {
+	if code < http.StatusMultipleChoices {
+		return nil
+	}
+	b, _ := io.ReadAll(body)
+	getLogger().Info("response is not ok status",
+		"status_code", code,
+		"body", string(b))
+
+	policy := matchPolicy(policies, code, StatusActionDelete)
+	statusErr := &StatusError{StatusCode: code, Body: string(b)}
+	if policy.Action == StatusActionRetry {
+		if d, ok := parseRetryAfter(header.Get("Retry-After"), time.Now()); ok {
+			return &RetryAfterError{After: d, Err: statusErr}
+		}
+	}
+	return policy.wrap(statusErr)
+}
+
+// setSqsdHeaders sets headers which Elastic Beanstalk worker environment's sqsd sends.
+func setSqsdHeaders(h http.Header, q Message) {
+	h.Set("X-Aws-Sqsd-Msgid", q.ID)
+	if q.QueueURL != "" {
+		h.Set("X-Aws-Sqsd-Queue", path.Base(q.QueueURL))
+	}
+	if ts := q.FirstReceiveTimestamp(); !ts.IsZero() {
+		h.Set("X-Aws-Sqsd-First-Received-At", ts.Format(time.RFC3339))
+	}
+	if n := q.ReceiveCount(); n > 0 {
+		h.Set("X-Aws-Sqsd-Receive-Count", strconv.Itoa(n))
+	}
+	if v := q.SenderID(); v != "" {
+		h.Set("X-Aws-Sqsd-Sender-Id", v)
+	}
+	if q.TaskName != "" {
+		h.Set("X-Aws-Sqsd-Taskname", q.TaskName)
+		h.Set("X-Aws-Sqsd-Scheduled-At", q.ScheduledAt.UTC().Format(time.RFC3339))
+	}
+	for name, attr := range q.Attributes {
+		h.Set("X-Aws-Sqsd-Attr-"+name, attributeHeaderValue(attr))
+	}
+}
+
+// attributeHeaderValue returns value of message attribute in header. binary value is encoded by base64.
+func attributeHeaderValue(attr MessageAttribute) string {
+	if attr.IsBinary() {
+		return base64.StdEncoding.EncodeToString(attr.BinaryValue)
 	}
-	return nil
+	return attr.StringValue
 }
@@ -3,6 +3,7 @@ package sqsd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -72,3 +73,172 @@ func TestHTTPInvoker(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPInvokerSqsdHeaders(t *testing.T) {
+	headerCh := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerCh <- r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	i, err := NewHTTPInvoker(srv.URL, time.Second)
+	assert.NoError(t, err)
+
+	err = i.Invoke(context.Background(), Message{
+		ID:       "msg-1",
+		Payload:  `{}`,
+		QueueURL: "http://localhost:9324/000000000000/my-queue",
+		SystemAttributes: map[string]string{
+			"ApproximateFirstReceiveTimestamp": "1700000000000",
+			"ApproximateReceiveCount":          "3",
+			"SenderId":                         "AIDAEXAMPLE",
+		},
+		Attributes: map[string]MessageAttribute{
+			"Kind": {DataType: "String", StringValue: "foo"},
+			"Num":  {DataType: "Number", StringValue: "42"},
+			"Raw":  {DataType: "Binary", BinaryValue: []byte("bar")},
+		},
+	})
+	assert.NoError(t, err)
+
+	h := <-headerCh
+	assert.Equal(t, "msg-1", h.Get("X-Aws-Sqsd-Msgid"))
+	assert.Equal(t, "my-queue", h.Get("X-Aws-Sqsd-Queue"))
+	assert.Equal(t, "2023-11-14T22:13:20Z", h.Get("X-Aws-Sqsd-First-Received-At"))
+	assert.Equal(t, "3", h.Get("X-Aws-Sqsd-Receive-Count"))
+	assert.Equal(t, "AIDAEXAMPLE", h.Get("X-Aws-Sqsd-Sender-Id"))
+	assert.Equal(t, "foo", h.Get("X-Aws-Sqsd-Attr-Kind"))
+	assert.Equal(t, "42", h.Get("X-Aws-Sqsd-Attr-Num"))
+	assert.Equal(t, "YmFy", h.Get("X-Aws-Sqsd-Attr-Raw"))
+}
+
+func TestHTTPInvokerPeriodicTask(t *testing.T) {
+	reqCh := make(chan *http.Request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCh <- r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	i, err := NewHTTPInvoker(srv.URL+"/worker", time.Second)
+	assert.NoError(t, err)
+
+	err = i.Invoke(context.Background(), Message{
+		ID:          "backup@1710504000",
+		TaskName:    "backup",
+		ScheduledAt: time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC),
+		Path:        "/backup",
+	})
+	assert.NoError(t, err)
+
+	r := <-reqCh
+	assert.Equal(t, "/backup", r.URL.Path)
+	assert.Equal(t, "backup", r.Header.Get("X-Aws-Sqsd-Taskname"))
+	assert.Equal(t, "2024-03-15T12:00:00Z", r.Header.Get("X-Aws-Sqsd-Scheduled-At"))
+}
+
+func TestHTTPInvokerStatusPolicies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := &invokerTestPayload{}
+		_ = json.NewDecoder(r.Body).Decode(p)
+		if v := r.URL.Query().Get("retry_after"); v != "" {
+			w.Header().Set("Retry-After", v)
+		}
+		w.WriteHeader(p.Status)
+	}))
+	defer srv.Close()
+
+	i, err := NewHTTPInvoker(srv.URL, time.Second, HTTPStatusPolicies(
+		StatusPolicy{Min: 404, Max: 404, Action: StatusActionDeadLetter},
+		StatusPolicy{Min: 409, Max: 409, Action: StatusActionRetain},
+		StatusPolicy{Min: 400, Max: 499, Action: StatusActionRetry, Delay: time.Minute},
+	))
+	assert.NoError(t, err)
+
+	for _, tt := range []struct {
+		label      string
+		status     int
+		retryAfter string
+		check      func(t *testing.T, err error)
+	}{
+		{
+			label:  "200",
+			status: http.StatusOK,
+			check: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			label:  "302 is deleted",
+			status: http.StatusFound,
+			check: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			label:  "404 is dead-lettered",
+			status: http.StatusNotFound,
+			check: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrDeadLetter)
+				var statusErr *StatusError
+				if assert.ErrorAs(t, err, &statusErr) {
+					assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+				}
+			},
+		},
+		{
+			label:  "409 is retained",
+			status: http.StatusConflict,
+			check: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrRetainMessage)
+			},
+		},
+		{
+			label:  "400 is retried with delay",
+			status: http.StatusBadRequest,
+			check: func(t *testing.T, err error) {
+				var retryErr *RetryAfterError
+				if assert.ErrorAs(t, err, &retryErr) {
+					assert.Equal(t, time.Minute, retryErr.After)
+				}
+			},
+		},
+		{
+			label:      "429 is retried by Retry-After",
+			status:     http.StatusTooManyRequests,
+			retryAfter: "120",
+			check: func(t *testing.T, err error) {
+				var retryErr *RetryAfterError
+				if assert.ErrorAs(t, err, &retryErr) {
+					assert.Equal(t, 2*time.Minute, retryErr.After)
+				}
+			},
+		},
+		{
+			label:  "503 is retried by backoff",
+			status: http.StatusServiceUnavailable,
+			check: func(t *testing.T, err error) {
+				var retryErr *RetryAfterError
+				assert.False(t, errors.As(err, &retryErr))
+				var statusErr *StatusError
+				if assert.ErrorAs(t, err, &statusErr) {
+					assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+				}
+			},
+		},
+	} {
+		t.Run(tt.label, func(t *testing.T) {
+			b, _ := json.Marshal(invokerTestPayload{Status: tt.status})
+			ivk := *i
+			if tt.retryAfter != "" {
+				u := *i.url
+				u.RawQuery = "retry_after=" + tt.retryAfter
+				ivk.url = &u
+			}
+			tt.check(t, ivk.Invoke(context.Background(), Message{
+				Payload: string(b),
+			}))
+		})
+	}
+}
@@ -7,8 +7,15 @@ import (
 )
 
 // QueueLocker represents locker interface for suppressing queue duplication.
+//
+// Lock marks key as processing, and returns ErrQueueExists if key is already processing or done.
+// Release removes processing mark, so that the same key can be locked again.
+// Done marks key as done, and the key is kept locked until Unlock removes it.
+// Unlock removes keys which are locked before supplied time.
 type QueueLocker interface {
 	Lock(ctx context.Context, key string) error
+	Release(ctx context.Context, key string) error
+	Done(ctx context.Context, key string) error
 	Unlock(ctx context.Context, before time.Time) error
 }
 
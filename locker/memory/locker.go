@@ -9,7 +9,9 @@ import (
 )
 
 type memoryLocker struct {
-	pool sync.Map
+	// pool holds done keys.
+	pool       sync.Map
+	processing sync.Map
 }
 
 // New creates QueueLocker by memory.
@@ -21,23 +23,39 @@ var _ locker.QueueLocker = (*memoryLocker)(nil)
 
 func (l *memoryLocker) Lock(_ context.Context, queueID string) error {
 	now := time.Now().UTC()
+	if _, loaded := l.processing.LoadOrStore(queueID, now); loaded {
+		return locker.ErrQueueExists
+	}
 	if _, ok := l.pool.Load(queueID); ok {
+		l.processing.Delete(queueID)
 		return locker.ErrQueueExists
 	}
-	l.pool.Store(queueID, now)
+	return nil
+}
+
+func (l *memoryLocker) Release(_ context.Context, queueID string) error {
+	l.processing.Delete(queueID)
+	return nil
+}
+
+func (l *memoryLocker) Done(_ context.Context, queueID string) error {
+	l.pool.Store(queueID, time.Now().UTC())
+	l.processing.Delete(queueID)
 	return nil
 }
 
 func (l *memoryLocker) Unlock(_ context.Context, ts time.Time) error {
-	var keys []interface{}
-	l.pool.Range(func(key, value interface{}) bool {
-		if value.(time.Time).Before(ts) {
-			keys = append(keys, key)
+	for _, pool := range []*sync.Map{&l.pool, &l.processing} {
+		var keys []interface{}
+		pool.Range(func(key, value interface{}) bool {
+			if value.(time.Time).Before(ts) {
+				keys = append(keys, key)
+			}
+			return true
+		})
+		for _, key := range keys {
+			pool.Delete(key)
 		}
-		return true
-	})
-	for _, key := range keys {
-		l.pool.Delete(key)
 	}
 	return nil
 }
@@ -56,3 +56,23 @@ func TestMemoryLocker(t *testing.T) {
 		})
 	}
 }
+
+func TestMemoryLockerReleaseAndDone(t *testing.T) {
+	l := New()
+	ctx := context.Background()
+
+	assert.NoError(t, l.Lock(ctx, "failed"))
+	assert.ErrorIs(t, l.Lock(ctx, "failed"), locker.ErrQueueExists)
+	assert.NoError(t, l.Release(ctx, "failed"))
+	assert.NoError(t, l.Lock(ctx, "failed"), "released message can be locked again")
+
+	assert.NoError(t, l.Lock(ctx, "succeeded"))
+	assert.NoError(t, l.Done(ctx, "succeeded"))
+	assert.ErrorIs(t, l.Lock(ctx, "succeeded"), locker.ErrQueueExists)
+	assert.NoError(t, l.Release(ctx, "succeeded"))
+	assert.ErrorIs(t, l.Lock(ctx, "succeeded"), locker.ErrQueueExists, "done message is kept locked")
+
+	assert.NoError(t, l.Unlock(ctx, time.Now().UTC().Add(time.Second)))
+	assert.NoError(t, l.Lock(ctx, "failed"))
+	assert.NoError(t, l.Lock(ctx, "succeeded"))
+}
@@ -8,14 +8,18 @@ import (
 )
 
 type noopLocker struct {
-	lockHooks   []func(context.Context, string) error
-	unlockHooks []func(context.Context, time.Time) error
+	lockHooks    []func(context.Context, string) error
+	releaseHooks []func(context.Context, string) error
+	doneHooks    []func(context.Context, string) error
+	unlockHooks  []func(context.Context, time.Time) error
 }
 
 // LockerWithHooks has noopLocker instance which has hooks interface.
 type LockerWithHooks interface {
 	locker.QueueLocker
 	AddLockHook(f func(context.Context, string) error)
+	AddReleaseHook(f func(context.Context, string) error)
+	AddDoneHook(f func(context.Context, string) error)
 	AddUnlockHook(f func(context.Context, time.Time) error)
 }
 
@@ -31,6 +35,20 @@ func (l *noopLocker) Lock(ctx context.Context, key string) error {
 	return nil
 }
 
+func (l *noopLocker) Release(ctx context.Context, key string) error {
+	for _, f := range l.releaseHooks {
+		_ = f(ctx, key)
+	}
+	return nil
+}
+
+func (l *noopLocker) Done(ctx context.Context, key string) error {
+	for _, f := range l.doneHooks {
+		_ = f(ctx, key)
+	}
+	return nil
+}
+
 func (l *noopLocker) Unlock(ctx context.Context, before time.Time) error {
 	for _, f := range l.unlockHooks {
 		_ = f(ctx, before)
@@ -42,6 +60,14 @@ func (l *noopLocker) AddLockHook(f func(context.Context, string) error) {
 	l.lockHooks = append(l.lockHooks, f)
 }
 
+func (l *noopLocker) AddReleaseHook(f func(context.Context, string) error) {
+	l.releaseHooks = append(l.releaseHooks, f)
+}
+
+func (l *noopLocker) AddDoneHook(f func(context.Context, string) error) {
+	l.doneHooks = append(l.doneHooks, f)
+}
+
 func (l *noopLocker) AddUnlockHook(f func(context.Context, time.Time) error) {
 	l.unlockHooks = append(l.unlockHooks, f)
 }
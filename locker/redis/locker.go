@@ -3,34 +3,128 @@ package redislocker
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/rueidis"
 	"github.com/taiyoh/sqsd/v2/locker"
 )
 
+// lockScript adds key to processing set only if key is not in done set.
+// processing keys which are locked before ARGV[3] are expired.
+var lockScript = rueidis.NewLuaScript(`
+if redis.call('ZSCORE', KEYS[1], ARGV[2]) then
+  return 0
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', ARGV[3])
+return redis.call('ZADD', KEYS[2], 'NX', ARGV[1], ARGV[2])
+`)
+
+// doneScript moves key from processing set to done set.
+var doneScript = rueidis.NewLuaScript(`
+redis.call('ZREM', KEYS[2], ARGV[2])
+return redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+`)
+
+// defaultProcessingExpire is the default duration to keep processing keys.
+const defaultProcessingExpire = 5 * time.Minute
+
 type redislocker struct {
-	keyName string
-	cli     rueidis.Client
+	// keyName is sorted set for done keys, and processingKeyName is for processing keys.
+	keyName           string
+	processingKeyName string
+	processingExpire  time.Duration
+	cli               rueidis.Client
 }
 
 var _ locker.QueueLocker = (*redislocker)(nil)
 
+// Option is an option for redis locker.
+type Option func(*redislocker)
+
+// ProcessingExpire sets duration to keep processing keys which are neither released nor done,
+// such as keys of messages which are held by crashed process.
+// it should be longer than invocation of message, because expired key can be locked by redelivered message again.
+// As default, it is 5 minutes.
+func ProcessingExpire(d time.Duration) Option {
+	return func(l *redislocker) {
+		l.processingExpire = d
+	}
+}
+
 // New creates QueueLocker by Redis.
-func New(cli rueidis.Client, keyName string) locker.QueueLocker {
-	return &redislocker{
-		keyName: keyName,
-		cli:     cli,
+func New(cli rueidis.Client, keyName string, opts ...Option) locker.QueueLocker {
+	l := &redislocker{
+		keyName:           keyName,
+		processingKeyName: processingKeyName(keyName),
+		processingExpire:  defaultProcessingExpire,
+		cli:               cli,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// processingKeyName returns key name which belongs to the same hash slot as keyName,
+// because lua scripts operate both keys.
+func processingKeyName(keyName string) string {
+	if _, ok := hashTag(keyName); ok {
+		return keyName + ":processing"
+	}
+	if !strings.Contains(keyName, "}") {
+		return "{" + keyName + "}:processing"
+	}
+	// whole keyName is hashed, but it can not be hash tag because it contains "}".
+	// so that another hash tag which has the same slot is used.
+	slot := keySlot(keyName)
+	for i := 0; ; i++ {
+		tag := strconv.Itoa(i)
+		if keySlot(tag) == slot {
+			return "{" + tag + "}" + keyName + ":processing"
+		}
+	}
+}
+
+// hashTag returns the string between the first "{" and the next "}" of key, only if it is not empty.
+// Redis Cluster hashes only hash tag if key has it.
+func hashTag(key string) (string, bool) {
+	i := strings.Index(key, "{")
+	if i < 0 {
+		return "", false
+	}
+	j := strings.Index(key[i+1:], "}")
+	if j <= 0 {
+		return "", false
+	}
+	return key[i+1 : i+1+j], true
+}
+
+// keySlot returns hash slot of key in Redis Cluster.
+func keySlot(key string) uint16 {
+	if tag, ok := hashTag(key); ok {
+		key = tag
+	}
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
 	}
+	return crc % 16384
 }
 
 func (l *redislocker) Lock(ctx context.Context, queueID string) error {
 	now := time.Now().UTC()
-	cmd := l.cli.B().Zadd().Key(l.keyName).Nx().ScoreMember().ScoreMember(
-		float64(now.UnixNano()),
-		queueID,
-	)
-	result := l.cli.Do(ctx, cmd.Build())
+	result := lockScript.Exec(ctx, l.cli,
+		[]string{l.keyName, l.processingKeyName},
+		[]string{fmt.Sprintf("%d", now.UnixNano()), queueID, fmt.Sprintf("%d", now.Add(-l.processingExpire).UnixNano())})
 	if err := result.Error(); err != nil {
 		return err
 	}
@@ -44,7 +138,25 @@ func (l *redislocker) Lock(ctx context.Context, queueID string) error {
 	return nil
 }
 
-func (l *redislocker) Unlock(ctx context.Context, ts time.Time) error {
-	cmd := l.cli.B().Zremrangebyscore().Key(l.keyName).Min("-inf").Max(fmt.Sprintf("%d", ts.UnixNano()))
+func (l *redislocker) Release(ctx context.Context, queueID string) error {
+	cmd := l.cli.B().Zrem().Key(l.processingKeyName).Member(queueID)
 	return l.cli.Do(ctx, cmd.Build()).Error()
 }
+
+func (l *redislocker) Done(ctx context.Context, queueID string) error {
+	now := time.Now().UTC()
+	return doneScript.Exec(ctx, l.cli,
+		[]string{l.keyName, l.processingKeyName},
+		[]string{fmt.Sprintf("%d", now.UnixNano()), queueID}).Error()
+}
+
+func (l *redislocker) Unlock(ctx context.Context, ts time.Time) error {
+	max := fmt.Sprintf("%d", ts.UnixNano())
+	for _, key := range []string{l.keyName, l.processingKeyName} {
+		cmd := l.cli.B().Zremrangebyscore().Key(key).Min("-inf").Max(max)
+		if err := l.cli.Do(ctx, cmd.Build()).Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -29,6 +29,7 @@ func TestRedsislocker(t *testing.T) {
 	ctx := context.Background()
 
 	assert.NoError(t, obj.Lock(ctx, "q1"))
+	assert.NoError(t, obj.Done(ctx, "q1"))
 	time.Sleep(10 * time.Millisecond)
 	t1 := time.Now() // after q1 lock
 
@@ -57,6 +58,7 @@ func TestRedsislocker(t *testing.T) {
 
 	time.Sleep(10 * time.Millisecond)
 	assert.NoError(t, obj.Lock(ctx, "q2"))
+	assert.NoError(t, obj.Done(ctx, "q2"))
 
 	t.Run("q2 not removed", func(t *testing.T) {
 		assert.NoError(t, obj.Unlock(ctx, t1))
@@ -76,3 +78,78 @@ func TestRedsislocker(t *testing.T) {
 		assert.Empty(t, ids)
 	})
 }
+
+func TestRedsislockerRelease(t *testing.T) {
+	db := rand.Intn(16)
+	cli, err := rueidis.NewClient(rueidis.ClientOption{
+		InitAddress: []string{"localhost:6379"},
+		SelectDB:    db,
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		cli.Do(context.Background(), cli.B().Flushdb().Build())
+		cli.Close()
+	})
+	obj := New(cli, "testReleaseKey")
+	ctx := context.Background()
+
+	assert.NoError(t, obj.Lock(ctx, "failed"))
+	assert.ErrorIs(t, obj.Lock(ctx, "failed"), locker.ErrQueueExists)
+	assert.NoError(t, obj.Release(ctx, "failed"))
+	assert.NoError(t, obj.Lock(ctx, "failed"), "released message can be locked again")
+
+	assert.NoError(t, obj.Lock(ctx, "succeeded"))
+	assert.NoError(t, obj.Done(ctx, "succeeded"))
+	assert.NoError(t, obj.Release(ctx, "succeeded"))
+	assert.ErrorIs(t, obj.Lock(ctx, "succeeded"), locker.ErrQueueExists, "done message is kept locked")
+
+	assert.NoError(t, obj.Unlock(ctx, time.Now()))
+	for _, key := range []string{"testReleaseKey", "{testReleaseKey}:processing"} {
+		result := cli.Do(ctx, cli.B().Zrangebyscore().Key(key).Min("-inf").Max("+inf").Build())
+		assert.NoError(t, result.Error())
+		ids, err := result.AsStrSlice()
+		assert.NoError(t, err)
+		assert.Empty(t, ids)
+	}
+}
+
+func TestRedsislockerProcessingExpire(t *testing.T) {
+	db := rand.Intn(16)
+	cli, err := rueidis.NewClient(rueidis.ClientOption{
+		InitAddress: []string{"localhost:6379"},
+		SelectDB:    db,
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		cli.Do(context.Background(), cli.B().Flushdb().Build())
+		cli.Close()
+	})
+	obj := New(cli, "testExpireKey", ProcessingExpire(50*time.Millisecond))
+	ctx := context.Background()
+
+	assert.NoError(t, obj.Lock(ctx, "crashed"))
+	assert.ErrorIs(t, obj.Lock(ctx, "crashed"), locker.ErrQueueExists)
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, obj.Lock(ctx, "crashed"), "expired processing key can be locked again")
+
+	assert.NoError(t, obj.Done(ctx, "crashed"))
+	time.Sleep(60 * time.Millisecond)
+	assert.ErrorIs(t, obj.Lock(ctx, "crashed"), locker.ErrQueueExists, "done key is not expired")
+}
+
+func TestProcessingKeyName(t *testing.T) {
+	assert.Equal(t, "{testKey}:processing", processingKeyName("testKey"))
+	assert.Equal(t, "{sqsd}:locker:processing", processingKeyName("{sqsd}:locker"))
+	assert.Equal(t, "{foo}bar}:processing", processingKeyName("{foo}bar}"))
+
+	// empty hash tag is ignored, and whole key is hashed.
+	key := processingKeyName("foo{}bar")
+	assert.NotEqual(t, "{foo{}bar}:processing", key)
+	assert.Equal(t, keySlot("foo{}bar"), keySlot(key))
+}
+
+func TestKeySlot(t *testing.T) {
+	assert.Equal(t, uint16(12739), keySlot("123456789"))
+	assert.Equal(t, keySlot("bar"), keySlot("foo{bar}baz"))
+	assert.NotEqual(t, keySlot("foo{"), keySlot("foo{}bar"))
+}
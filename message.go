@@ -0,0 +1,131 @@
+package sqsd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of message system attributes.
+const (
+	AttributeSenderID                         = "SenderId"
+	AttributeSentTimestamp                    = "SentTimestamp"
+	AttributeApproximateReceiveCount          = "ApproximateReceiveCount"
+	AttributeApproximateFirstReceiveTimestamp = "ApproximateFirstReceiveTimestamp"
+	AttributeSequenceNumber                   = "SequenceNumber"
+	AttributeMessageDeduplicationID           = "MessageDeduplicationId"
+	AttributeMessageGroupID                   = "MessageGroupId"
+	AttributeAWSTraceHeader                   = "AWSTraceHeader"
+)
+
+// Message provides transition from sqs.Message
+type Message struct {
+	ID         string
+	Payload    string
+	Receipt    string
+	ReceivedAt time.Time
+	// VisibilityTimeout is VisibilityTimeout which this message is received with.
+	VisibilityTimeout time.Duration
+	// QueueURL is URL of the queue which this message is received from.
+	QueueURL string
+	// SystemAttributes holds message system attributes, such as ApproximateReceiveCount.
+	SystemAttributes map[string]string
+	// Attributes holds user-specified message attributes.
+	Attributes map[string]MessageAttribute
+	// TaskName is name of periodic task. it is set by Scheduler only.
+	TaskName string
+	// ScheduledAt is scheduled time of periodic task. it is set by Scheduler only.
+	ScheduledAt time.Time
+	// Path overrides request path of invoker. it is set by Scheduler only.
+	Path string
+}
+
+// SenderID returns SenderId system attribute.
+func (m Message) SenderID() string {
+	return m.SystemAttributes[AttributeSenderID]
+}
+
+// SentTimestamp returns SentTimestamp system attribute.
+// if it is not received, returns zero time.
+func (m Message) SentTimestamp() time.Time {
+	return m.timestampAttribute(AttributeSentTimestamp)
+}
+
+// ReceiveCount returns ApproximateReceiveCount system attribute.
+// if it is not received, returns 0.
+func (m Message) ReceiveCount() int {
+	n, _ := strconv.Atoi(m.SystemAttributes[AttributeApproximateReceiveCount])
+	return n
+}
+
+// FirstReceiveTimestamp returns ApproximateFirstReceiveTimestamp system attribute.
+// if it is not received, returns zero time.
+func (m Message) FirstReceiveTimestamp() time.Time {
+	return m.timestampAttribute(AttributeApproximateFirstReceiveTimestamp)
+}
+
+// SequenceNumber returns SequenceNumber system attribute of FIFO queue.
+func (m Message) SequenceNumber() string {
+	return m.SystemAttributes[AttributeSequenceNumber]
+}
+
+// MessageDeduplicationID returns MessageDeduplicationId system attribute of FIFO queue.
+func (m Message) MessageDeduplicationID() string {
+	return m.SystemAttributes[AttributeMessageDeduplicationID]
+}
+
+// MessageGroupID returns MessageGroupId system attribute of FIFO queue.
+func (m Message) MessageGroupID() string {
+	return m.SystemAttributes[AttributeMessageGroupID]
+}
+
+// AWSTraceHeader returns AWSTraceHeader system attribute for AWS X-Ray.
+func (m Message) AWSTraceHeader() string {
+	return m.SystemAttributes[AttributeAWSTraceHeader]
+}
+
+func (m Message) timestampAttribute(name string) time.Time {
+	ms, err := strconv.ParseInt(m.SystemAttributes[name], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+// MessageAttribute provides transition from sqs.MessageAttributeValue.
+type MessageAttribute struct {
+	DataType    string
+	StringValue string
+	BinaryValue []byte
+}
+
+// IsString reports whether attribute's DataType is String or its custom type.
+func (a MessageAttribute) IsString() bool {
+	return a.baseType() == "String"
+}
+
+// IsNumber reports whether attribute's DataType is Number or its custom type.
+func (a MessageAttribute) IsNumber() bool {
+	return a.baseType() == "Number"
+}
+
+// IsBinary reports whether attribute's DataType is Binary or its custom type.
+func (a MessageAttribute) IsBinary() bool {
+	return a.baseType() == "Binary"
+}
+
+// Int64 returns Number attribute value as int64.
+func (a MessageAttribute) Int64() (int64, error) {
+	return strconv.ParseInt(a.StringValue, 10, 64)
+}
+
+// Float64 returns Number attribute value as float64.
+func (a MessageAttribute) Float64() (float64, error) {
+	return strconv.ParseFloat(a.StringValue, 64)
+}
+
+// baseType returns DataType without custom type label. (e.g. "Number.float" -> "Number")
+func (a MessageAttribute) baseType() string {
+	t, _, _ := strings.Cut(a.DataType, ".")
+	return t
+}
@@ -0,0 +1,83 @@
+package sqsd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageSystemAttributes(t *testing.T) {
+	msg := Message{
+		SystemAttributes: map[string]string{
+			AttributeSenderID:                         "AIDAEXAMPLE",
+			AttributeSentTimestamp:                    "1700000000000",
+			AttributeApproximateReceiveCount:          "2",
+			AttributeApproximateFirstReceiveTimestamp: "1700000001000",
+			AttributeSequenceNumber:                   "18849496460467696128",
+			AttributeMessageDeduplicationID:           "dedup-1",
+			AttributeMessageGroupID:                   "group-1",
+			AttributeAWSTraceHeader:                   "Root=1-5759e988-bd862e3fe1be46a994272793",
+		},
+	}
+	assert.Equal(t, "AIDAEXAMPLE", msg.SenderID())
+	assert.Equal(t, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC), msg.SentTimestamp())
+	assert.Equal(t, 2, msg.ReceiveCount())
+	assert.Equal(t, time.Date(2023, 11, 14, 22, 13, 21, 0, time.UTC), msg.FirstReceiveTimestamp())
+	assert.Equal(t, "18849496460467696128", msg.SequenceNumber())
+	assert.Equal(t, "dedup-1", msg.MessageDeduplicationID())
+	assert.Equal(t, "group-1", msg.MessageGroupID())
+	assert.Equal(t, "Root=1-5759e988-bd862e3fe1be46a994272793", msg.AWSTraceHeader())
+
+	var empty Message
+	assert.Empty(t, empty.SenderID())
+	assert.True(t, empty.SentTimestamp().IsZero())
+	assert.Zero(t, empty.ReceiveCount())
+	assert.True(t, empty.FirstReceiveTimestamp().IsZero())
+}
+
+func TestMessageAttribute(t *testing.T) {
+	for _, tt := range []struct {
+		label    string
+		attr     MessageAttribute
+		isString bool
+		isNumber bool
+		isBinary bool
+	}{
+		{
+			label:    "string",
+			attr:     MessageAttribute{DataType: "String", StringValue: "foo"},
+			isString: true,
+		},
+		{
+			label:    "custom string",
+			attr:     MessageAttribute{DataType: "String.json", StringValue: `{"foo":"bar"}`},
+			isString: true,
+		},
+		{
+			label:    "number",
+			attr:     MessageAttribute{DataType: "Number", StringValue: "42"},
+			isNumber: true,
+		},
+		{
+			label:    "binary",
+			attr:     MessageAttribute{DataType: "Binary", BinaryValue: []byte("bar")},
+			isBinary: true,
+		},
+	} {
+		t.Run(tt.label, func(t *testing.T) {
+			assert.Equal(t, tt.isString, tt.attr.IsString())
+			assert.Equal(t, tt.isNumber, tt.attr.IsNumber())
+			assert.Equal(t, tt.isBinary, tt.attr.IsBinary())
+		})
+	}
+
+	i, err := MessageAttribute{DataType: "Number", StringValue: "42"}.Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), i)
+	f, err := MessageAttribute{DataType: "Number.float", StringValue: "4.2"}.Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, 4.2, f)
+	_, err = MessageAttribute{DataType: "String", StringValue: "foo"}.Int64()
+	assert.Error(t, err)
+}
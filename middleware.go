@@ -0,0 +1,150 @@
+package sqsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"time"
+)
+
+// InvokerFunc is an adapter to use ordinary function as Invoker.
+type InvokerFunc func(context.Context, Message) error
+
+// Invoke calls f(ctx, msg).
+func (f InvokerFunc) Invoke(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+// Middleware wraps Invoker to add behavior before and after invocation.
+type Middleware func(Invoker) Invoker
+
+// ChainMiddlewares wraps invoker by middlewares.
+// The first middleware is the outermost, so that it runs first.
+func ChainMiddlewares(ivk Invoker, mws ...Middleware) Invoker {
+	if len(mws) == 0 {
+		return ivk
+	}
+	wrapped := ivk
+	for i := len(mws) - 1; i >= 0; i-- {
+		wrapped = mws[i](wrapped)
+	}
+	return middlewareInvoker{Invoker: wrapped, base: ivk}
+}
+
+// middlewareInvoker keeps the invoker before wrapped, because invokers returned by middlewares can not be unwrapped.
+type middlewareInvoker struct {
+	Invoker
+	base Invoker
+}
+
+// Unwrap returns the invoker which is wrapped by middlewares.
+func (i middlewareInvoker) Unwrap() Invoker {
+	return i.base
+}
+
+// ConsumerMiddlewares wraps invoker of consumer by middlewares, in the same order as ChainMiddlewares.
+// Invokers of GatewayInvoker are also wrapped, but periodic tasks of Scheduler are not.
+func ConsumerMiddlewares(mws ...Middleware) ConsumerParameter {
+	return func(p *consumerParams) {
+		p.middlewares = append(p.middlewares, mws...)
+	}
+}
+
+// PanicError is returned when invoker panics.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("invoker panics: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recoverInvoke calls invoker, and converts its panic to PanicError with logging stack trace.
+func recoverInvoke(ctx context.Context, ivk Invoker, msg Message) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			stack := debug.Stack()
+			getLogger().Error("invoker panics",
+				"message_id", msg.ID,
+				"panic", fmt.Sprint(v),
+				"stack", string(stack))
+			err = &PanicError{Value: v, Stack: stack}
+		}
+	}()
+	return ivk.Invoke(ctx, msg)
+}
+
+// MiddlewareRecover converts panic of invoker to PanicError, and logs its stack trace.
+// Message is retried in the same way as other errors.
+// Worker of consumer always recovers panic, so this is for invokers which are used outside of consumer.
+func MiddlewareRecover() Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, msg Message) error {
+			return recoverInvoke(ctx, next, msg)
+		})
+	}
+}
+
+// MiddlewareTimeout cancels context of invocation after d.
+func MiddlewareTimeout(d time.Duration) Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, msg Message) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next.Invoke(ctx, msg)
+		})
+	}
+}
+
+// MiddlewareAccessLog writes a log line per invocation, with its duration and error.
+func MiddlewareAccessLog() Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, msg Message) error {
+			startedAt := time.Now()
+			err := next.Invoke(ctx, msg)
+			logger := getLogger().With(
+				"message_id", msg.ID,
+				"queue_url", msg.QueueURL,
+				"receive_count", msg.ReceiveCount(),
+				"duration", time.Since(startedAt).String())
+			if msg.TaskName != "" {
+				logger = logger.With("task_name", msg.TaskName)
+			}
+			if err != nil {
+				logger.Info("message is invoked", "error", err.Error())
+			} else {
+				logger.Info("message is invoked")
+			}
+			return err
+		})
+	}
+}
+
+// ErrFilteredMessage shows that message is not invoked by MiddlewareAttributeFilter.
+var ErrFilteredMessage = errors.New("message is filtered")
+
+// MiddlewareAttributeFilter invokes only messages whose attribute of name has any of values.
+// If values are empty, messages which have the attribute are invoked.
+// Other messages are handled by action without invocation, such as StatusActionDelete or StatusActionRetain.
+func MiddlewareAttributeFilter(action StatusAction, name string, values ...string) Middleware {
+	policy := StatusPolicy{Action: action}
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(ctx context.Context, msg Message) error {
+			attr, ok := msg.Attributes[name]
+			if ok && (len(values) == 0 || slices.Contains(values, attr.StringValue)) {
+				return next.Invoke(ctx, msg)
+			}
+			getLogger().Debug("message is filtered", "message_id", msg.ID, "attribute", name)
+			return policy.wrap(ErrFilteredMessage)
+		})
+	}
+}
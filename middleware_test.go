@@ -0,0 +1,122 @@
+package sqsd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainMiddlewares(t *testing.T) {
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next Invoker) Invoker {
+			return InvokerFunc(func(ctx context.Context, msg Message) error {
+				calls = append(calls, name+":before")
+				err := next.Invoke(ctx, msg)
+				calls = append(calls, name+":after")
+				return err
+			})
+		}
+	}
+	ivk := InvokerFunc(func(context.Context, Message) error {
+		calls = append(calls, "invoke")
+		return nil
+	})
+	assert.NoError(t, ChainMiddlewares(ivk, mw("outer"), mw("inner")).Invoke(context.Background(), Message{}))
+	assert.Equal(t, []string{"outer:before", "inner:before", "invoke", "inner:after", "outer:after"}, calls)
+
+	pool := &ProcessPoolInvoker{}
+	assert.Equal(t, []*ProcessPoolInvoker{pool}, processPools(ChainMiddlewares(pool, mw("outer"))))
+}
+
+func TestMiddlewareRecover(t *testing.T) {
+	ivk := ChainMiddlewares(InvokerFunc(func(_ context.Context, msg Message) error {
+		switch msg.ID {
+		case "error":
+			panic(errors.New("broken"))
+		case "value":
+			panic("broken")
+		}
+		return nil
+	}), MiddlewareRecover())
+
+	ctx := context.Background()
+	assert.NoError(t, ivk.Invoke(ctx, Message{ID: "ok"}))
+
+	err := ivk.Invoke(ctx, Message{ID: "value"})
+	var panicErr *PanicError
+	if assert.ErrorAs(t, err, &panicErr) {
+		assert.Equal(t, "broken", panicErr.Value)
+		assert.Contains(t, string(panicErr.Stack), "middleware_test.go")
+	}
+	assert.EqualError(t, err, "invoker panics: broken")
+
+	err = ivk.Invoke(ctx, Message{ID: "error"})
+	assert.EqualError(t, errors.Unwrap(err), "broken")
+}
+
+func TestMiddlewareTimeout(t *testing.T) {
+	ivk := ChainMiddlewares(InvokerFunc(func(ctx context.Context, _ Message) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}), MiddlewareTimeout(50*time.Millisecond), MiddlewareAccessLog())
+	assert.ErrorIs(t, ivk.Invoke(context.Background(), Message{ID: "slow"}), context.DeadlineExceeded)
+}
+
+func TestMiddlewareAttributeFilter(t *testing.T) {
+	var invoked []string
+	ivk := InvokerFunc(func(_ context.Context, msg Message) error {
+		invoked = append(invoked, msg.ID)
+		return nil
+	})
+	withTenant := func(id, tenant string) Message {
+		return Message{ID: id, Attributes: map[string]MessageAttribute{
+			"tenant": {DataType: "String", StringValue: tenant},
+		}}
+	}
+
+	ctx := context.Background()
+	filtered := ChainMiddlewares(ivk, MiddlewareAttributeFilter(StatusActionRetain, "tenant", "a", "b"))
+	assert.NoError(t, filtered.Invoke(ctx, withTenant("1", "a")))
+	assert.NoError(t, filtered.Invoke(ctx, withTenant("2", "b")))
+	err := filtered.Invoke(ctx, withTenant("3", "c"))
+	assert.ErrorIs(t, err, ErrRetainMessage)
+	assert.ErrorIs(t, err, ErrFilteredMessage)
+	assert.ErrorIs(t, filtered.Invoke(ctx, Message{ID: "4"}), ErrFilteredMessage)
+
+	exists := ChainMiddlewares(ivk, MiddlewareAttributeFilter(StatusActionDelete, "tenant"))
+	assert.NoError(t, exists.Invoke(ctx, withTenant("5", "c")))
+	assert.NoError(t, exists.Invoke(ctx, Message{ID: "6"}))
+
+	assert.Equal(t, []string{"1", "2", "5"}, invoked)
+}
+
+func TestConsumerMiddlewares(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	testInvokerFn := func(ctx context.Context, q Message) error {
+		if q.ID == "panic" {
+			panic("broken")
+		}
+		return nil
+	}
+
+	op := &testQueueOperator{}
+	broker := make(chan Message, 1)
+	startWorker(ctx, testInvoker(testInvokerFn), broker, op,
+		ConsumerMiddlewares(MiddlewareRecover(), MiddlewareAttributeFilter(StatusActionDelete, "type", "job")))
+
+	job := map[string]MessageAttribute{"type": {DataType: "String", StringValue: "job"}}
+	broker <- Message{ID: "panic", Attributes: job}
+	broker <- Message{ID: "succeeded", Attributes: job}
+	broker <- Message{ID: "filtered"}
+	time.Sleep(50 * time.Millisecond)
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	assert.Equal(t, []string{"succeeded", "filtered"}, op.removed)
+	assert.Equal(t, []string{"panic"}, op.released)
+}
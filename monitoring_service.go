@@ -3,6 +3,9 @@ package sqsd
 import (
 	"context"
 	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // MonitoringService provides grpc handler for MonitoringService.
@@ -21,7 +24,48 @@ func NewMonitoringService(consumer *worker) *MonitoringService {
 // CurrentWorkings handles CurrentWorkings grpc request using actor system.
 func (s *MonitoringService) CurrentWorkings(ctx context.Context, _ *CurrentWorkingsRequest) (*CurrentWorkingsResponse, error) {
 	tasks := s.worker.CurrentWorkings(ctx)
-	return &CurrentWorkingsResponse{Tasks: tasks}, nil
+	groups := s.worker.ActiveGroups(ctx)
+	procs := s.worker.Processes(ctx)
+	return &CurrentWorkingsResponse{Tasks: tasks, ActiveGroups: groups, Processes: procs}, nil
+}
+
+// Stats handles Stats grpc request.
+func (s *MonitoringService) Stats(ctx context.Context, _ *StatsRequest) (*StatsResponse, error) {
+	return s.worker.Stats(ctx), nil
+}
+
+// Pause handles Pause grpc request.
+func (s *MonitoringService) Pause(ctx context.Context, _ *PauseRequest) (*PauseResponse, error) {
+	return &PauseResponse{State: s.worker.Pause(ctx)}, nil
+}
+
+// Resume handles Resume grpc request.
+func (s *MonitoringService) Resume(ctx context.Context, _ *ResumeRequest) (*ResumeResponse, error) {
+	return &ResumeResponse{State: s.worker.Resume(ctx)}, nil
+}
+
+// Drain handles Drain grpc request.
+func (s *MonitoringService) Drain(ctx context.Context, _ *DrainRequest) (*DrainResponse, error) {
+	state, released := s.worker.Drain(ctx)
+	return &DrainResponse{State: state, Released: int32(released)}, nil
+}
+
+// Status handles Status grpc request.
+func (s *MonitoringService) Status(ctx context.Context, _ *StatusRequest) (*StatusResponse, error) {
+	return s.worker.Status(ctx), nil
+}
+
+// SetConcurrency handles SetConcurrency grpc request.
+func (s *MonitoringService) SetConcurrency(ctx context.Context, req *SetConcurrencyRequest) (*SetConcurrencyResponse, error) {
+	n := int(req.GetConcurrency())
+	if n < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "concurrency must be positive: %d", n)
+	}
+	prev, err := s.worker.SetConcurrency(ctx, n)
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	return &SetConcurrencyResponse{Concurrency: int32(n), Previous: int32(prev)}, nil
 }
 
 // WaitUntilAllEnds waits until all worker tasks finishes.
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMonitoringService(t *testing.T) {
@@ -24,7 +25,7 @@ func TestMonitoringService(t *testing.T) {
 	defer cancel()
 
 	broker := make(chan Message, 3)
-	w := startWorker(ctx, testInvoker(testInvokerFn), broker, &Gateway{})
+	w := startWorker(ctx, testInvoker(testInvokerFn), broker, NewGateway(newTestQueueBackend(), ""))
 	monitor := NewMonitoringService(w)
 
 	resp, err := monitor.CurrentWorkings(ctx, nil)
@@ -122,3 +123,27 @@ func TestMonitoringService(t *testing.T) {
 	nextCh <- struct{}{}
 	assert.NoError(t, <-errCh)
 }
+
+func TestMonitoringServiceProcesses(t *testing.T) {
+	ivk, err := NewProcessPoolInvoker("pool:///bin/cat", time.Second, ProcessPoolKillDelay(100*time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, ivk.start(2))
+	defer ivk.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := make(chan Message, 2)
+	w := startWorker(ctx, NewUnwrapInvoker(ivk), broker, NewGateway(newTestQueueBackend(), ""))
+	monitor := NewMonitoringService(w)
+
+	resp, err := monitor.CurrentWorkings(ctx, nil)
+	require.NoError(t, err)
+	procs := resp.GetProcesses()
+	require.Len(t, procs, 2)
+	for _, p := range procs {
+		assert.Equal(t, "/bin/cat", p.GetCommand())
+		assert.Equal(t, ProcessStateIdle, p.GetState())
+		assert.NotZero(t, p.GetPid())
+	}
+}
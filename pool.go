@@ -0,0 +1,525 @@
+package sqsd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// States of worker process in ProcessPoolInvoker.
+const (
+	ProcessStateIdle    = "idle"
+	ProcessStateBusy    = "busy"
+	ProcessStateStopped = "stopped"
+)
+
+// ProcessPoolInvoker invokes messages by long-lived worker processes.
+// Each process receives a message as a JSON line on stdin, and writes the result as a JSON line on stdout.
+// Headers of HTTPInvoker are given as "headers", but names of message attributes are not canonicalized.
+//
+//	stdin:  {"id":"msg-1","payload":"...","headers":{"X-Aws-Sqsd-Msgid":"msg-1","X-Aws-Sqsd-Queue":"jobs"}}
+//	stdout: {"id":"msg-1","code":0}
+//
+// Code 0 means success, and other codes are mapped to actions by policies, in the same way as exit codes of ExecInvoker.
+// Lines which are not JSON are ignored, so that worker process can write logs to stdout.
+//
+// Processes are supervised: a process which exits is restarted, a process which exceeds timeout is killed,
+// and a process which handles max jobs is recycled.
+// Pool size follows the parallel count of ConsumerBuilder unless ProcessPoolSize is given.
+type ProcessPoolInvoker struct {
+	path      string
+	args      []string
+	timeout   time.Duration
+	dir       string
+	policies  []StatusPolicy
+	maxJobs   int
+	killDelay time.Duration
+
+	mu    sync.Mutex
+	size  int
+	slots []*poolSlot
+	idle  chan *poolSlot
+}
+
+type processPoolParams struct {
+	policies  []StatusPolicy
+	dir       string
+	size      int
+	maxJobs   int
+	killDelay time.Duration
+}
+
+// ProcessPoolParameter sets parameter to ProcessPoolInvoker by functional option pattern.
+type ProcessPoolParameter func(*processPoolParams)
+
+// ProcessPoolCodePolicies maps result codes to actions, with the same format as status policies.
+func ProcessPoolCodePolicies(policies ...StatusPolicy) ProcessPoolParameter {
+	return func(p *processPoolParams) {
+		p.policies = append(p.policies, policies...)
+	}
+}
+
+// ProcessPoolWorkDir sets working directory of worker processes.
+func ProcessPoolWorkDir(dir string) ProcessPoolParameter {
+	return func(p *processPoolParams) {
+		p.dir = dir
+	}
+}
+
+// ProcessPoolSize sets the number of worker processes.
+// By default, it is the same as parallel count of ConsumerBuilder.
+func ProcessPoolSize(size int) ProcessPoolParameter {
+	return func(p *processPoolParams) {
+		p.size = size
+	}
+}
+
+// ProcessPoolMaxJobs makes worker process recycled after handling n messages. default is 0, which means unlimited.
+func ProcessPoolMaxJobs(n int) ProcessPoolParameter {
+	return func(p *processPoolParams) {
+		p.maxJobs = n
+	}
+}
+
+// ProcessPoolKillDelay sets the duration between stopping request and SIGKILL. default is 5 seconds.
+// Process is stopped by closing stdin when it is recycled, and by SIGTERM when it exceeds timeout.
+func ProcessPoolKillDelay(d time.Duration) ProcessPoolParameter {
+	return func(p *processPoolParams) {
+		p.killDelay = d
+	}
+}
+
+// NewProcessPoolInvoker returns ProcessPoolInvoker instance.
+// rawurl is "pool:///path/to/command", and arguments are given as "arg" query.
+// Worker processes are started by System, or by the first invocation.
+//
+//	pool:///usr/local/bin/worker?arg=--verbose&arg=jobs
+func NewProcessPoolInvoker(rawurl string, dur time.Duration, params ...ProcessPoolParameter) (*ProcessPoolInvoker, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "pool" || u.Host != "" || u.Path == "" {
+		return nil, fmt.Errorf("pool URL must be pool:///path/to/command: %s", rawurl)
+	}
+	p := processPoolParams{
+		killDelay: 5 * time.Second,
+	}
+	for _, fn := range params {
+		fn(&p)
+	}
+	return &ProcessPoolInvoker{
+		path:      u.Path,
+		args:      u.Query()["arg"],
+		timeout:   dur,
+		dir:       p.dir,
+		policies:  p.policies,
+		maxJobs:   p.maxJobs,
+		killDelay: p.killDelay,
+		size:      p.size,
+	}, nil
+}
+
+// ResultCodeError shows that worker process returns non-zero code.
+type ResultCodeError struct {
+	Code    int
+	Message string
+}
+
+func (e *ResultCodeError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("worker process returns code %d", e.Code)
+	}
+	return fmt.Sprintf("worker process returns code %d: %s", e.Code, e.Message)
+}
+
+type poolRequest struct {
+	ID      string            `json:"id"`
+	Payload string            `json:"payload"`
+	Headers map[string]string `json:"headers"`
+}
+
+// poolHeaders returns headers of HTTPInvoker for q.
+// Names of message attributes are kept as they are, because JSON line is not HTTP and they are not canonicalized.
+func poolHeaders(q Message) map[string]string {
+	attrs := q.Attributes
+	q.Attributes = nil
+	h := make(http.Header)
+	setSqsdHeaders(h, q)
+	headers := make(map[string]string, len(h)+len(attrs))
+	for name, values := range h {
+		headers[name] = values[0]
+	}
+	for name, attr := range attrs {
+		headers["X-Aws-Sqsd-Attr-"+name] = attributeHeaderValue(attr)
+	}
+	return headers
+}
+
+type poolResponse struct {
+	ID    string `json:"id"`
+	Code  int    `json:"code"`
+	Error string `json:"error,omitempty"`
+}
+
+// start starts worker processes. size is used when ProcessPoolSize is not given.
+// it does nothing when processes are already started.
+func (ivk *ProcessPoolInvoker) start(size int) error {
+	ivk.mu.Lock()
+	defer ivk.mu.Unlock()
+	if ivk.slots != nil {
+		return nil
+	}
+	if ivk.size < 1 {
+		ivk.size = size
+	}
+	if ivk.size < 1 {
+		ivk.size = 1
+	}
+	slots := make([]*poolSlot, ivk.size)
+	idle := make(chan *poolSlot, ivk.size)
+	for i := range slots {
+		proc, err := ivk.spawn()
+		if err != nil {
+			for _, s := range slots[:i] {
+				s.proc.stop(nil, ivk.killDelay)
+			}
+			return fmt.Errorf("failed to start worker process: %w", err)
+		}
+		slots[i] = &poolSlot{index: i, proc: proc, state: ProcessStateIdle}
+		idle <- slots[i]
+	}
+	ivk.slots = slots
+	ivk.idle = idle
+	getLogger().Info("worker processes are started", "command", ivk.path, "size", ivk.size)
+	return nil
+}
+
+// Close stops all worker processes by closing their stdin.
+func (ivk *ProcessPoolInvoker) Close() error {
+	ivk.mu.Lock()
+	slots := ivk.slots
+	ivk.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, s := range slots {
+		s.mu.Lock()
+		proc := s.proc
+		s.proc, s.state = nil, ProcessStateStopped
+		s.mu.Unlock()
+		if proc == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			proc.stop(nil, ivk.killDelay)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// Processes returns states of worker processes.
+func (ivk *ProcessPoolInvoker) Processes() []*WorkerProcess {
+	ivk.mu.Lock()
+	slots := ivk.slots
+	ivk.mu.Unlock()
+	procs := make([]*WorkerProcess, 0, len(slots))
+	for _, s := range slots {
+		procs = append(procs, s.snapshot(ivk.path))
+	}
+	return procs
+}
+
+// Invoke sends message to idle worker process, and waits for its result.
+func (ivk *ProcessPoolInvoker) Invoke(ctx context.Context, q Message) error {
+	if err := ivk.start(1); err != nil {
+		return err
+	}
+	if ivk.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ivk.timeout)
+		defer cancel()
+	}
+	req := poolRequest{ID: q.ID, Payload: q.Payload, Headers: poolHeaders(q)}
+	line, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	var slot *poolSlot
+	select {
+	case slot = <-ivk.idle:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { ivk.idle <- slot }()
+
+	proc, err := slot.acquire(ivk, q.ID)
+	if err != nil {
+		return err
+	}
+	startedAt := time.Now()
+	resp, err := proc.do(ctx, append(line, '\n'), q.ID)
+	logger := getLogger().With(
+		"message_id", q.ID,
+		"slot", slot.index,
+		"pid", proc.cmd.Process.Pid,
+		"duration", time.Since(startedAt).String())
+	if err != nil {
+		logger.Warn("worker process is killed", "error", err)
+		slot.discard()
+		go proc.stop(syscall.SIGTERM, ivk.killDelay)
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+		return err
+	}
+	if jobs := slot.release(); ivk.maxJobs > 0 && jobs >= ivk.maxJobs {
+		logger.Info("worker process is recycled", "jobs", jobs)
+		slot.discard()
+		go proc.stop(nil, ivk.killDelay)
+	}
+	if resp.Code == 0 {
+		logger.Debug("worker process is succeeded")
+		return nil
+	}
+	logger.Info("worker process is failed", "code", resp.Code, "error", resp.Error)
+	return matchPolicy(ivk.policies, resp.Code, StatusActionRetry).wrap(&ResultCodeError{Code: resp.Code, Message: resp.Error})
+}
+
+// spawn starts worker process.
+// stdin and stdout are *os.File, so that deadlines can be set to them, and Wait does not wait for descendants which inherit them.
+func (ivk *ProcessPoolInvoker) spawn() (*poolProcess, error) {
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		stdinR.Close()
+		stdinW.Close()
+		return nil, err
+	}
+	cmd := exec.Command(ivk.path, ivk.args...)
+	cmd.Dir = ivk.dir
+	cmd.Stdin = stdinR
+	cmd.Stdout = stdoutW
+	cmd.Stderr = os.Stderr
+	err = cmd.Start()
+	stdinR.Close()
+	stdoutW.Close()
+	if err != nil {
+		stdinW.Close()
+		stdoutR.Close()
+		return nil, err
+	}
+	p := &poolProcess{
+		cmd:       cmd,
+		stdin:     stdinW,
+		responses: make(chan poolResponse, 1),
+		done:      make(chan struct{}),
+		exited:    make(chan struct{}),
+		startedAt: time.Now(),
+	}
+	go p.read(stdoutR)
+	go func() {
+		err := cmd.Wait()
+		close(p.exited)
+		getLogger().Debug("worker process exits", "pid", cmd.Process.Pid, "error", err)
+		// descendants may keep stdout open, so reading is finished after remaining output is read.
+		_ = stdoutR.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	}()
+	return p, nil
+}
+
+// poolSlot holds worker process, which is replaced when it exits.
+type poolSlot struct {
+	index     int
+	mu        sync.Mutex
+	proc      *poolProcess
+	state     string
+	messageID string
+	restarts  int32
+}
+
+// acquire returns running process of slot, and marks it busy.
+// process is started again when it is exited or stopped.
+func (s *poolSlot) acquire(ivk *ProcessPoolInvoker, messageID string) (*poolProcess, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.proc != nil && s.proc.isDone() {
+		getLogger().Warn("worker process exits unexpectedly", "slot", s.index, "pid", s.proc.cmd.Process.Pid)
+		s.proc = nil
+	}
+	if s.proc == nil {
+		proc, err := ivk.spawn()
+		if err != nil {
+			s.state = ProcessStateStopped
+			return nil, fmt.Errorf("failed to restart worker process: %w", err)
+		}
+		s.proc = proc
+		s.restarts++
+	}
+	s.state, s.messageID = ProcessStateBusy, messageID
+	return s.proc, nil
+}
+
+// release marks slot idle after process handles message, and returns the number of jobs of process.
+func (s *poolSlot) release() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proc.jobs++
+	s.state, s.messageID = ProcessStateIdle, ""
+	return s.proc.jobs
+}
+
+// discard detaches process from slot, and it is replaced at next acquire.
+func (s *poolSlot) discard() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proc, s.state, s.messageID = nil, ProcessStateStopped, ""
+}
+
+func (s *poolSlot) snapshot(command string) *WorkerProcess {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wp := &WorkerProcess{
+		Command:   command,
+		Slot:      int32(s.index),
+		State:     s.state,
+		Restarts:  s.restarts,
+		MessageId: s.messageID,
+	}
+	if s.proc != nil {
+		wp.Pid = int32(s.proc.cmd.Process.Pid)
+		wp.Jobs = int64(s.proc.jobs)
+		wp.StartedAt = timestamppb.New(s.proc.startedAt)
+	}
+	return wp
+}
+
+type poolProcess struct {
+	cmd       *exec.Cmd
+	stdin     *os.File
+	responses chan poolResponse
+	// done is closed when stdout is closed, after all responses are sent.
+	done chan struct{}
+	// exited is closed when process exits.
+	exited    chan struct{}
+	startedAt time.Time
+	jobs      int
+}
+
+func (p *poolProcess) read(stdout *os.File) {
+	defer close(p.done)
+	defer stdout.Close()
+	r := bufio.NewReader(stdout)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			var resp poolResponse
+			if jsonErr := json.Unmarshal(line, &resp); jsonErr != nil {
+				getLogger().Info("worker process wrote non-JSON line", "pid", p.cmd.Process.Pid, "line", string(line))
+			} else {
+				select {
+				case p.responses <- resp:
+				default:
+					getLogger().Warn("worker process wrote unexpected response", "pid", p.cmd.Process.Pid, "id", resp.ID)
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (p *poolProcess) isDone() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// do writes request line to process, and waits for the response of it.
+func (p *poolProcess) do(ctx context.Context, line []byte, id string) (poolResponse, error) {
+	defer deadlineOnDone(ctx, p.stdin.SetWriteDeadline)()
+	if _, err := p.stdin.Write(line); err != nil {
+		return poolResponse{}, fmt.Errorf("failed to write to worker process: %w", err)
+	}
+	select {
+	case resp := <-p.responses:
+		return resp, checkPoolResponse(resp, id)
+	case <-p.done:
+		// response may be written just before process exits.
+		select {
+		case resp := <-p.responses:
+			return resp, checkPoolResponse(resp, id)
+		default:
+		}
+		return poolResponse{}, errors.New("worker process exits without response")
+	case <-ctx.Done():
+		return poolResponse{}, errors.New("worker process does not respond")
+	}
+}
+
+func checkPoolResponse(resp poolResponse, id string) error {
+	if resp.ID != id {
+		return fmt.Errorf("worker process responds to another message: %q", resp.ID)
+	}
+	return nil
+}
+
+// stop sends sig to process, or closes stdin if sig is nil, and kills it if it does not exit in delay.
+func (p *poolProcess) stop(sig os.Signal, delay time.Duration) {
+	if sig == nil {
+		p.stdin.Close()
+	} else {
+		_ = p.cmd.Process.Signal(sig)
+	}
+	select {
+	case <-p.exited:
+	case <-time.After(delay):
+		_ = p.cmd.Process.Kill()
+		<-p.exited
+	}
+	p.stdin.Close()
+}
+
+// processPools returns ProcessPoolInvokers which are contained in ivk by Unwrap method,
+// so that System starts their processes with parallel count and reports their states.
+func processPools(ivk Invoker) []*ProcessPoolInvoker {
+	var pools []*ProcessPoolInvoker
+	var walk func(Invoker)
+	walk = func(ivk Invoker) {
+		switch v := ivk.(type) {
+		case *ProcessPoolInvoker:
+			if !slices.Contains(pools, v) {
+				pools = append(pools, v)
+			}
+		case interface{ Unwrap() Invoker }:
+			walk(v.Unwrap())
+		case interface{ Unwrap() []Invoker }:
+			for _, inner := range v.Unwrap() {
+				walk(inner)
+			}
+		}
+	}
+	walk(ivk)
+	return pools
+}
@@ -0,0 +1,193 @@
+package sqsd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// poolTestScript responds to each line by the attribute "action" in headers.
+const poolTestScript = `
+while IFS= read -r line; do
+  id=$(echo "$line" | sed 's/^{"id":"\([^"]*\)".*/\1/')
+  case "$line" in
+    *'"X-Aws-Sqsd-Attr-action":"crash"'*) exit 1 ;;
+    *'"X-Aws-Sqsd-Attr-action":"slow"'*) exec sleep 10 ;;
+    *'"X-Aws-Sqsd-Attr-action":"fail"'*) echo "{\"id\":\"$id\",\"code\":75,\"error\":\"failed\"}"; continue ;;
+    *'"X-Aws-Sqsd-Attr-action":"deadletter"'*) echo "{\"id\":\"$id\",\"code\":70}"; continue ;;
+  esac
+  echo "processing $id"
+  echo "$line" >> "$OUT"
+  echo "{\"id\":\"$id\",\"code\":0}"
+done
+`
+
+func withAction(msg Message, action string) Message {
+	msg.Attributes = map[string]MessageAttribute{"action": {DataType: "String", StringValue: action}}
+	return msg
+}
+
+func TestPoolHeaders(t *testing.T) {
+	headers := poolHeaders(Message{
+		ID: "msg-1",
+		Attributes: map[string]MessageAttribute{
+			"myAttr":  {DataType: "String", StringValue: "foo"},
+			"binAttr": {DataType: "Binary", BinaryValue: []byte("bar")},
+		},
+	})
+	assert.Equal(t, map[string]string{
+		"X-Aws-Sqsd-Msgid":        "msg-1",
+		"X-Aws-Sqsd-Attr-myAttr":  "foo",
+		"X-Aws-Sqsd-Attr-binAttr": "YmFy",
+	}, headers)
+}
+
+func TestProcessPoolInvoker(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	t.Setenv("OUT", out)
+	ivk, err := NewProcessPoolInvoker("pool://"+writeTestScript(t, poolTestScript), 300*time.Millisecond,
+		ProcessPoolMaxJobs(3),
+		ProcessPoolKillDelay(100*time.Millisecond),
+		ProcessPoolCodePolicies(
+			StatusPolicy{Min: 75, Max: 75, Action: StatusActionRetry, Delay: 30 * time.Second},
+			StatusPolicy{Min: 64, Max: 78, Action: StatusActionDeadLetter},
+		))
+	require.NoError(t, err)
+	require.NoError(t, ivk.start(2))
+	defer ivk.Close()
+
+	procs := ivk.Processes()
+	require.Len(t, procs, 2)
+	for i, p := range procs {
+		assert.Equal(t, int32(i), p.Slot)
+		assert.Equal(t, ProcessStateIdle, p.State)
+		assert.NotZero(t, p.Pid)
+	}
+
+	ctx := context.Background()
+	msg := Message{
+		ID:       "msg-1",
+		Payload:  `{"hello":"world"}`,
+		QueueURL: "http://localhost:9324/000000000000/my-queue",
+	}
+	require.NoError(t, ivk.Invoke(ctx, msg))
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"payload":"{\"hello\":\"world\"}"`)
+	assert.Contains(t, string(b), `"X-Aws-Sqsd-Queue":"my-queue"`)
+
+	err = ivk.Invoke(ctx, withAction(msg, "fail"))
+	var retryErr *RetryAfterError
+	if assert.ErrorAs(t, err, &retryErr) {
+		assert.Equal(t, 30*time.Second, retryErr.After)
+	}
+	var codeErr *ResultCodeError
+	if assert.ErrorAs(t, err, &codeErr) {
+		assert.Equal(t, 75, codeErr.Code)
+		assert.Equal(t, "failed", codeErr.Message)
+	}
+
+	err = ivk.Invoke(ctx, withAction(msg, "deadletter"))
+	assert.ErrorIs(t, err, ErrDeadLetter)
+
+	var jobs int64
+	for _, p := range ivk.Processes() {
+		jobs += p.Jobs
+	}
+	assert.Equal(t, int64(3), jobs)
+}
+
+func TestProcessPoolInvokerSupervise(t *testing.T) {
+	t.Setenv("OUT", filepath.Join(t.TempDir(), "out"))
+	ivk, err := NewProcessPoolInvoker("pool://"+writeTestScript(t, poolTestScript), 300*time.Millisecond,
+		ProcessPoolSize(1),
+		ProcessPoolMaxJobs(2),
+		ProcessPoolKillDelay(100*time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, ivk.start(4))
+	defer ivk.Close()
+	require.Len(t, ivk.Processes(), 1, "ProcessPoolSize has priority")
+
+	ctx := context.Background()
+	msg := Message{ID: "msg-1", Payload: "{}"}
+	pid := ivk.Processes()[0].Pid
+
+	// process exits without response.
+	err = ivk.Invoke(ctx, withAction(msg, "crash"))
+	assert.Error(t, err)
+	assert.Equal(t, ProcessStateStopped, ivk.Processes()[0].State)
+
+	// process is restarted.
+	require.NoError(t, ivk.Invoke(ctx, msg))
+	p := ivk.Processes()[0]
+	assert.NotEqual(t, pid, p.Pid)
+	assert.Equal(t, int32(1), p.Restarts)
+	assert.Equal(t, int64(1), p.Jobs)
+	pid = p.Pid
+
+	// process is recycled after max jobs.
+	require.NoError(t, ivk.Invoke(ctx, msg))
+	assert.Equal(t, ProcessStateStopped, ivk.Processes()[0].State)
+	require.NoError(t, ivk.Invoke(ctx, msg))
+	assert.NotEqual(t, pid, ivk.Processes()[0].Pid)
+	pid = ivk.Processes()[0].Pid
+
+	// process is killed when it exceeds timeout.
+	startedAt := time.Now()
+	err = ivk.Invoke(ctx, withAction(msg, "slow"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(startedAt), 5*time.Second)
+	require.NoError(t, ivk.Invoke(ctx, msg))
+	assert.NotEqual(t, pid, ivk.Processes()[0].Pid)
+}
+
+func TestProcessPoolInvokerURL(t *testing.T) {
+	for _, rawurl := range []string{
+		"pool://localhost/bin/cat",
+		"pool://",
+		"exec:///bin/cat",
+	} {
+		_, err := NewProcessPoolInvoker(rawurl, time.Second)
+		assert.Error(t, err, rawurl)
+	}
+	ivk, err := NewProcessPoolInvoker("pool:///bin/cat?arg=-u", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "/bin/cat", ivk.path)
+	assert.Equal(t, []string{"-u"}, ivk.args)
+
+	ivk, err = NewProcessPoolInvoker("pool:///path/to/missing", time.Second)
+	require.NoError(t, err)
+	err = ivk.Invoke(context.Background(), Message{ID: "msg-1"})
+	assert.ErrorContains(t, err, "failed to start worker process")
+}
+
+// testWrapInvoker is an invoker of user which wraps another invoker.
+type testWrapInvoker struct {
+	Invoker
+}
+
+func (i testWrapInvoker) Unwrap() Invoker {
+	return i.Invoker
+}
+
+func TestProcessPools(t *testing.T) {
+	p1 := &ProcessPoolInvoker{path: "/bin/p1"}
+	p2 := &ProcessPoolInvoker{path: "/bin/p2"}
+	p3 := &ProcessPoolInvoker{path: "/bin/p3"}
+	ivk := queueInvokers{
+		fallback: NewUnwrapInvoker(p1),
+		invokers: map[string]Invoker{
+			"queue-1": p1,
+			"queue-2": testWrapInvoker{p2},
+			"queue-3": testInvoker(func(context.Context, Message) error { return nil }),
+			"queue-4": NewUnwrapInvoker(testWrapInvoker{ChainMiddlewares(p3, MiddlewareRecover())}),
+		},
+	}
+	assert.Equal(t, []*ProcessPoolInvoker{p1, p2, p3}, processPools(ivk))
+	assert.Empty(t, processPools(testInvoker(nil)))
+}
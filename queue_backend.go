@@ -0,0 +1,58 @@
+package sqsd
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// QueueBackend is the queue service which gateway receives messages from.
+// SQSBackend is the default implementation.
+type QueueBackend interface {
+	// Receive receives messages from queue.
+	// ReceivedAt and QueueURL of messages are filled by gateway.
+	Receive(ctx context.Context, in ReceiveInput) ([]Message, error)
+	// DeleteMessages deletes messages by receipt handles at once.
+	// it returns errors of each entry in the same order of receipts, and nil means the entry is deleted.
+	// if whole request is failed, it returns error as second value.
+	DeleteMessages(ctx context.Context, queueURL string, receipts []string) ([]error, error)
+	// ChangeVisibility changes VisibilityTimeout of message.
+	ChangeVisibility(ctx context.Context, queueURL, receipt string, timeout time.Duration) error
+	// Send sends message to queue, and returns its message id.
+	Send(ctx context.Context, in SendInput) (string, error)
+}
+
+// ReceiveInput is the parameter of receiving messages.
+type ReceiveInput struct {
+	QueueURL          string
+	MaxMessages       int
+	WaitTime          time.Duration
+	VisibilityTimeout time.Duration
+	// SystemAttributes and MessageAttributes are names of attributes to be received,
+	// and "All" means all attributes.
+	SystemAttributes  []string
+	MessageAttributes []string
+}
+
+// SendInput is the parameter of sending message.
+type SendInput struct {
+	QueueURL   string
+	Body       string
+	Attributes map[string]MessageAttribute
+	Delay      time.Duration
+	// MessageGroupID and DeduplicationID are required for FIFO queue.
+	MessageGroupID  string
+	DeduplicationID string
+}
+
+// BatchEntryError shows that an entry of batch request is failed.
+// if SenderFault is true, request of the entry itself is wrong, so retrying it is meaningless.
+type BatchEntryError struct {
+	Code        string
+	Message     string
+	SenderFault bool
+}
+
+func (e *BatchEntryError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
@@ -0,0 +1,89 @@
+package sqsd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// testQueueBackend is in-memory QueueBackend for tests.
+type testQueueBackend struct {
+	mu       sync.Mutex
+	queue    []Message
+	inflight map[string]Message
+	deleted  []string
+	changed  map[string]time.Duration
+	sent     []SendInput
+	seq      int
+}
+
+func newTestQueueBackend() *testQueueBackend {
+	return &testQueueBackend{
+		inflight: make(map[string]Message),
+		changed:  make(map[string]time.Duration),
+	}
+}
+
+func (b *testQueueBackend) push(msgs ...Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.queue = append(b.queue, msgs...)
+}
+
+func (b *testQueueBackend) Receive(ctx context.Context, in ReceiveInput) ([]Message, error) {
+	b.mu.Lock()
+	n := in.MaxMessages
+	if n > len(b.queue) {
+		n = len(b.queue)
+	}
+	msgs := b.queue[:n:n]
+	b.queue = b.queue[n:]
+	for i := range msgs {
+		if msgs[i].Receipt == "" {
+			b.seq++
+			msgs[i].Receipt = fmt.Sprintf("receipt-%d", b.seq)
+		}
+		b.inflight[msgs[i].Receipt] = msgs[i]
+	}
+	b.mu.Unlock()
+	if n == 0 {
+		// emulates long polling.
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return msgs, nil
+}
+
+func (b *testQueueBackend) DeleteMessages(_ context.Context, _ string, receipts []string) ([]error, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	errs := make([]error, len(receipts))
+	for i, receipt := range receipts {
+		if _, ok := b.inflight[receipt]; !ok {
+			errs[i] = &BatchEntryError{Code: "ReceiptHandleIsInvalid", SenderFault: true}
+			continue
+		}
+		delete(b.inflight, receipt)
+		b.deleted = append(b.deleted, receipt)
+	}
+	return errs, nil
+}
+
+func (b *testQueueBackend) ChangeVisibility(_ context.Context, _, receipt string, timeout time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.changed[receipt] = timeout
+	return nil
+}
+
+func (b *testQueueBackend) Send(_ context.Context, in SendInput) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	b.sent = append(b.sent, in)
+	return fmt.Sprintf("sent-%d", b.seq), nil
+}
@@ -0,0 +1,102 @@
+package sqsd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// maxVisibilityTimeout is the upper limit of VisibilityTimeout in SQS.
+const maxVisibilityTimeout = 12 * time.Hour
+
+// RetryAfterError shows that invoking is failed and message should be retried after the duration.
+// If invoker returns this error, worker sets VisibilityTimeout of message to the duration,
+// instead of the duration from RetryPolicy.
+type RetryAfterError struct {
+	After time.Duration
+	Err   error
+}
+
+// RetryAfter returns RetryAfterError with duration.
+func RetryAfter(d time.Duration) error {
+	return &RetryAfterError{After: d}
+}
+
+func (e *RetryAfterError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("retry after %s: %s", e.After, e.Err)
+	}
+	return fmt.Sprintf("retry after %s", e.After)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryPolicy decides VisibilityTimeout of failed message by exponential backoff.
+// Delay grows as Base * Multiplier^(ApproximateReceiveCount - 1), and is limited between Min and Max.
+// Jitter is the ratio of randomly reduced delay, between 0 and 1.
+type RetryPolicy struct {
+	Base       time.Duration
+	Multiplier float64
+	Min        time.Duration
+	Max        time.Duration
+	Jitter     float64
+}
+
+// backoff returns delay for the message which is received receiveCount times.
+func (p RetryPolicy) backoff(receiveCount int) time.Duration {
+	if receiveCount < 1 {
+		receiveCount = 1
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	max := p.Max
+	if max <= 0 || max > maxVisibilityTimeout {
+		max = maxVisibilityTimeout
+	}
+	d := float64(p.Base) * math.Pow(multiplier, float64(receiveCount-1))
+	if d > float64(max) {
+		d = float64(max)
+	}
+	if jitter := math.Min(p.Jitter, 1); jitter > 0 {
+		d -= d * jitter * rand.Float64()
+	}
+	delay := time.Duration(d)
+	if delay < p.Min {
+		delay = p.Min
+	}
+	return delay
+}
+
+// ConsumerRetryPolicy sets RetryPolicy to consumer.
+// When invoking is failed, worker changes VisibilityTimeout of the message by the policy.
+func ConsumerRetryPolicy(p RetryPolicy) ConsumerParameter {
+	return func(c *consumerParams) {
+		c.retryPolicy = &p
+	}
+}
+
+// retryDelay returns VisibilityTimeout for failed message.
+// if false is returned, VisibilityTimeout should not be changed.
+func (c consumerParams) retryDelay(msg Message, err error) (time.Duration, bool) {
+	var retryAfter *RetryAfterError
+	if errors.As(err, &retryAfter) {
+		d := retryAfter.After
+		if d < 0 {
+			d = 0
+		}
+		if d > maxVisibilityTimeout {
+			d = maxVisibilityTimeout
+		}
+		return d, true
+	}
+	if c.retryPolicy == nil {
+		return 0, false
+	}
+	return c.retryPolicy.backoff(msg.ReceiveCount()), true
+}
@@ -0,0 +1,73 @@
+package sqsd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{
+		Base: time.Second,
+		Min:  2 * time.Second,
+		Max:  time.Minute,
+	}
+	for _, tt := range []struct {
+		receiveCount int
+		want         time.Duration
+	}{
+		{0, 2 * time.Second},
+		{1, 2 * time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{7, time.Minute},
+		{100, time.Minute},
+	} {
+		t.Run(fmt.Sprintf("receive count %d", tt.receiveCount), func(t *testing.T) {
+			assert.Equal(t, tt.want, p.backoff(tt.receiveCount))
+		})
+	}
+
+	p = RetryPolicy{
+		Base:       10 * time.Second,
+		Multiplier: 3,
+		Jitter:     0.5,
+	}
+	for i := 0; i < 100; i++ {
+		d := p.backoff(2)
+		assert.LessOrEqual(t, d, 30*time.Second)
+		assert.GreaterOrEqual(t, d, 15*time.Second)
+	}
+
+	// Max is limited by SQS restriction.
+	assert.Equal(t, 12*time.Hour, RetryPolicy{Base: time.Hour}.backoff(10))
+}
+
+func TestRetryDelay(t *testing.T) {
+	msg := Message{
+		SystemAttributes: map[string]string{
+			AttributeApproximateReceiveCount: "3",
+		},
+	}
+
+	var c consumerParams
+	_, ok := c.retryDelay(msg, errors.New("failed"))
+	assert.False(t, ok, "without policy, visibility timeout is not changed")
+
+	d, ok := c.retryDelay(msg, fmt.Errorf("wrapped: %w", RetryAfter(5*time.Second)))
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, d)
+
+	ConsumerRetryPolicy(RetryPolicy{Base: time.Second})(&c)
+	d, ok = c.retryDelay(msg, errors.New("failed"))
+	assert.True(t, ok)
+	assert.Equal(t, 4*time.Second, d)
+
+	d, ok = c.retryDelay(msg, RetryAfter(24*time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, 12*time.Hour, d)
+}
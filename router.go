@@ -0,0 +1,83 @@
+package sqsd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// gatewayRouter dispatches queue operations to the gateway which message is received from.
+type gatewayRouter map[string]*Gateway
+
+func (r gatewayRouter) gateway(msg Message) (*Gateway, error) {
+	g, ok := r[msg.QueueURL]
+	if !ok {
+		return nil, fmt.Errorf("unknown queue: %s", msg.QueueURL)
+	}
+	return g, nil
+}
+
+func (r gatewayRouter) remove(ctx context.Context, msg Message) error {
+	g, err := r.gateway(msg)
+	if err != nil {
+		return err
+	}
+	return g.remove(ctx, msg)
+}
+
+func (r gatewayRouter) release(ctx context.Context, msg Message) error {
+	g, err := r.gateway(msg)
+	if err != nil {
+		return err
+	}
+	return g.release(ctx, msg)
+}
+
+func (r gatewayRouter) changeVisibility(ctx context.Context, msg Message, timeout time.Duration) error {
+	g, err := r.gateway(msg)
+	if err != nil {
+		return err
+	}
+	return g.changeVisibility(ctx, msg, timeout)
+}
+
+func (r gatewayRouter) isFIFO(msg Message) bool {
+	g, err := r.gateway(msg)
+	return err == nil && g.isFIFO(msg)
+}
+
+func (r gatewayRouter) inflightDeletes() int64 {
+	var n int64
+	for _, g := range r {
+		n += g.inflightDeletes()
+	}
+	return n
+}
+
+// queueInvokers invokes message by the invoker of its queue, or by the default invoker.
+type queueInvokers struct {
+	fallback Invoker
+	invokers map[string]Invoker
+}
+
+// Unwrap returns the default invoker and invokers of queues in order of queue URL.
+func (i queueInvokers) Unwrap() []Invoker {
+	urls := make([]string, 0, len(i.invokers))
+	for url := range i.invokers {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	invokers := []Invoker{i.fallback}
+	for _, url := range urls {
+		invokers = append(invokers, i.invokers[url])
+	}
+	return invokers
+}
+
+func (i queueInvokers) Invoke(ctx context.Context, msg Message) error {
+	if ivk, ok := i.invokers[msg.QueueURL]; ok {
+		return ivk.Invoke(ctx, msg)
+	}
+	return i.fallback.Invoke(ctx, msg)
+}
@@ -0,0 +1,48 @@
+package sqsd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGatewayRouter(t *testing.T) {
+	ctx := context.Background()
+	fifo := NewGateway(nil, "http://localhost:9324/queue/a.fifo")
+	std := NewGateway(nil, "http://localhost:9324/queue/b")
+	r := gatewayRouter{
+		fifo.queueURL: fifo,
+		std.queueURL:  std,
+	}
+
+	assert.True(t, r.isFIFO(Message{QueueURL: fifo.queueURL}))
+	assert.False(t, r.isFIFO(Message{QueueURL: std.queueURL}))
+	assert.False(t, r.isFIFO(Message{QueueURL: "http://localhost:9324/queue/c.fifo"}))
+
+	assert.NoError(t, r.release(ctx, Message{QueueURL: std.queueURL}))
+	assert.EqualError(t, r.release(ctx, Message{QueueURL: "unknown"}), "unknown queue: unknown")
+	assert.EqualError(t, r.remove(ctx, Message{QueueURL: "unknown"}), "unknown queue: unknown")
+	assert.EqualError(t, r.changeVisibility(ctx, Message{QueueURL: "unknown"}, 0), "unknown queue: unknown")
+	assert.Equal(t, int64(0), r.inflightDeletes())
+}
+
+func TestQueueInvokers(t *testing.T) {
+	var invoked []string
+	newInvoker := func(name string) Invoker {
+		return testInvoker(func(ctx context.Context, q Message) error {
+			invoked = append(invoked, name+":"+q.ID)
+			return nil
+		})
+	}
+	ivk := queueInvokers{
+		fallback: newInvoker("default"),
+		invokers: map[string]Invoker{
+			"queue-a": newInvoker("a"),
+		},
+	}
+	ctx := context.Background()
+	assert.NoError(t, ivk.Invoke(ctx, Message{ID: "1", QueueURL: "queue-a"}))
+	assert.NoError(t, ivk.Invoke(ctx, Message{ID: "2", QueueURL: "queue-b"}))
+	assert.Equal(t, []string{"a:1", "default:2"}, invoked)
+}
@@ -0,0 +1,180 @@
+package sqsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/taiyoh/sqsd/v2/locker"
+	nooplocker "github.com/taiyoh/sqsd/v2/locker/noop"
+)
+
+// PeriodicTask represents a task of cron.yaml in Elastic Beanstalk worker environments.
+type PeriodicTask struct {
+	Name     string `yaml:"name"`
+	URL      string `yaml:"url"`
+	Schedule string `yaml:"schedule"`
+}
+
+type cronConfig struct {
+	Version int            `yaml:"version"`
+	Cron    []PeriodicTask `yaml:"cron"`
+}
+
+// LoadPeriodicTasks reads periodic tasks from cron.yaml formatted file.
+func LoadPeriodicTasks(path string) ([]PeriodicTask, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var conf cronConfig
+	if err := yaml.Unmarshal(b, &conf); err != nil {
+		return nil, err
+	}
+	if conf.Version != 1 {
+		return nil, fmt.Errorf("unsupported cron.yaml version: %d", conf.Version)
+	}
+	return conf.Cron, nil
+}
+
+type schedulerParams struct {
+	locker locker.QueueLocker
+}
+
+// SchedulerParameter sets parameter to scheduler by functional option pattern.
+type SchedulerParameter func(*schedulerParams)
+
+// SchedulerQueueLocker sets QueueLocker to scheduler for leader election.
+// Only one process which locks a tick of task invokes it,
+// so locker must be shared between processes, such as redis locker.
+func SchedulerQueueLocker(l locker.QueueLocker) SchedulerParameter {
+	return func(p *schedulerParams) {
+		p.locker = l
+	}
+}
+
+type scheduledTask struct {
+	PeriodicTask
+	schedule *cronSchedule
+}
+
+// Scheduler invokes periodic tasks on schedule.
+type Scheduler struct {
+	tasks  []scheduledTask
+	locker locker.QueueLocker
+	wg     sync.WaitGroup
+}
+
+// NewScheduler returns Scheduler object.
+func NewScheduler(tasks []PeriodicTask, params ...SchedulerParameter) (*Scheduler, error) {
+	param := schedulerParams{
+		locker: nooplocker.Get(),
+	}
+	for _, fn := range params {
+		fn(&param)
+	}
+	s := &Scheduler{
+		locker: param.locker,
+	}
+	for _, task := range tasks {
+		if task.Name == "" {
+			return nil, errors.New("name is required for periodic task")
+		}
+		if task.URL == "" {
+			return nil, fmt.Errorf("url is required for periodic task: %s", task.Name)
+		}
+		schedule, err := parseCronSchedule(task.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("periodic task %s: %w", task.Name, err)
+		}
+		s.tasks = append(s.tasks, scheduledTask{
+			PeriodicTask: task,
+			schedule:     schedule,
+		})
+	}
+	return s, nil
+}
+
+// nextTick returns the earliest scheduled time after t and tasks which are scheduled at the time.
+func (s *Scheduler) nextTick(t time.Time) (time.Time, []scheduledTask) {
+	var next time.Time
+	var tasks []scheduledTask
+	for _, task := range s.tasks {
+		ts := task.schedule.next(t)
+		switch {
+		case ts.IsZero():
+		case next.IsZero() || ts.Before(next):
+			next = ts
+			tasks = []scheduledTask{task}
+		case ts.Equal(next):
+			tasks = append(tasks, task)
+		}
+	}
+	return next, tasks
+}
+
+// Run invokes periodic tasks until ctx is cancelled.
+// After ctx is cancelled, it waits until all running tasks finish.
+func (s *Scheduler) Run(ctx context.Context, ivk Invoker) {
+	defer s.wg.Wait()
+	logger := getLogger()
+	for {
+		now := time.Now().UTC()
+		next, tasks := s.nextTick(now)
+		if next.IsZero() {
+			logger.Warn("no periodic task is scheduled.")
+			return
+		}
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		for _, task := range tasks {
+			s.wg.Add(1)
+			go func(task scheduledTask) {
+				defer s.wg.Done()
+				s.invoke(ctx, ivk, task, next)
+			}(task)
+		}
+	}
+}
+
+func (s *Scheduler) invoke(ctx context.Context, ivk Invoker, task scheduledTask, scheduledAt time.Time) {
+	id := fmt.Sprintf("%s@%d", task.Name, scheduledAt.Unix())
+	logger := getLogger().With("task_name", task.Name, "scheduled_at", scheduledAt.Format(time.RFC3339))
+	if err := s.locker.Lock(ctx, id); err != nil {
+		if err == locker.ErrQueueExists {
+			logger.Debug("periodic task is invoked by other process.")
+		} else {
+			logger.Error("failed to lock", "error", err)
+		}
+		return
+	}
+	// periodic task is not retried as well as Elastic Beanstalk, so the tick keeps locked as done.
+	defer func() {
+		if err := s.locker.Done(context.Background(), id); err != nil {
+			logger.Error("failed to mark periodic task as done", "error", err)
+		}
+	}()
+	logger.Debug("start to invoke periodic task.")
+	// invoking runs with new context object as well as worker does.
+	if err := recoverInvoke(context.Background(), ivk, Message{
+		ID:          id,
+		ReceivedAt:  time.Now().UTC(),
+		TaskName:    task.Name,
+		ScheduledAt: scheduledAt,
+		Path:        task.URL,
+	}); err != nil {
+		logger.Error("failed to invoke periodic task.", "error", err)
+		return
+	}
+	logger.Debug("succeeded to invoke periodic task.")
+}
@@ -0,0 +1,91 @@
+package sqsd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	memorylocker "github.com/taiyoh/sqsd/v2/locker/memory"
+)
+
+func TestLoadPeriodicTasks(t *testing.T) {
+	dir := t.TempDir()
+	fp := filepath.Join(dir, "cron.yaml")
+	assert.NoError(t, os.WriteFile(fp, []byte(`version: 1
+cron:
+  - name: "backup-job"
+    url: "/backup"
+    schedule: "0 */12 * * *"
+  - name: "audit"
+    url: "/audit"
+    schedule: "* * * * *"
+`), 0o600))
+
+	tasks, err := LoadPeriodicTasks(fp)
+	assert.NoError(t, err)
+	assert.Equal(t, []PeriodicTask{
+		{Name: "backup-job", URL: "/backup", Schedule: "0 */12 * * *"},
+		{Name: "audit", URL: "/audit", Schedule: "* * * * *"},
+	}, tasks)
+
+	assert.NoError(t, os.WriteFile(fp, []byte("version: 2\ncron: []\n"), 0o600))
+	_, err = LoadPeriodicTasks(fp)
+	assert.Error(t, err)
+}
+
+func TestNewScheduler(t *testing.T) {
+	_, err := NewScheduler([]PeriodicTask{{URL: "/foo", Schedule: "* * * * *"}})
+	assert.Error(t, err)
+	_, err = NewScheduler([]PeriodicTask{{Name: "foo", Schedule: "* * * * *"}})
+	assert.Error(t, err)
+	_, err = NewScheduler([]PeriodicTask{{Name: "foo", URL: "/foo", Schedule: "* * *"}})
+	assert.Error(t, err)
+
+	s, err := NewScheduler([]PeriodicTask{
+		{Name: "foo", URL: "/foo", Schedule: "*/10 * * * *"},
+		{Name: "bar", URL: "/bar", Schedule: "*/5 * * * *"},
+		{Name: "baz", URL: "/baz", Schedule: "0 * * * *"},
+	})
+	assert.NoError(t, err)
+
+	next, tasks := s.nextTick(time.Date(2024, 3, 15, 10, 7, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 3, 15, 10, 10, 0, 0, time.UTC), next)
+	if assert.Len(t, tasks, 2) {
+		assert.Equal(t, "foo", tasks[0].Name)
+		assert.Equal(t, "bar", tasks[1].Name)
+	}
+}
+
+func TestSchedulerInvokeWithLocker(t *testing.T) {
+	l := memorylocker.New()
+	received := make(chan Message, 2)
+	ivk := testInvoker(func(ctx context.Context, msg Message) error {
+		received <- msg
+		return nil
+	})
+
+	task := scheduledTask{PeriodicTask: PeriodicTask{Name: "backup", URL: "/backup"}}
+	scheduledAt := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	// 2 processes share locker, so only one of them invokes the task.
+	for i := 0; i < 2; i++ {
+		s, err := NewScheduler(nil, SchedulerQueueLocker(l))
+		assert.NoError(t, err)
+		s.invoke(context.Background(), ivk, task, scheduledAt)
+	}
+	close(received)
+
+	var msgs []Message
+	for msg := range received {
+		msgs = append(msgs, msg)
+	}
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, "backup", msgs[0].TaskName)
+		assert.Equal(t, "/backup", msgs[0].Path)
+		assert.Equal(t, scheduledAt, msgs[0].ScheduledAt)
+	}
+}
@@ -0,0 +1,255 @@
+package sqsd
+
+import (
+	"context"
+	"sync"
+)
+
+// slots counts free slots of worker.
+// Fetcher waits for free slots before receiving messages, reserves slots for received messages,
+// and worker frees a slot after processing a message, so that worker never holds messages more than it can process.
+// Slots are not reserved while fetcher waits for messages by long polling, so that idle queues do not hold them.
+//
+// Slots are shared by queues as tenants.
+// A tenant can not reserve slots while any tenant of higher priority is waiting for slots,
+// and tenants of the same priority share slots by their weights.
+type slots struct {
+	mu      sync.Mutex
+	size    int
+	used    int
+	tenants map[string]*slotTenant
+	// paused stops reserving slots, so that fetchers stop receiving messages.
+	paused bool
+	// freed is closed and renewed when any slots are freed or waiting tenants are changed.
+	freed chan struct{}
+}
+
+type slotTenant struct {
+	weight   int
+	priority int
+	// max is the upper limit of slots which tenant uses at once, and 0 means unlimited.
+	max     int
+	used    int
+	waiting int
+}
+
+func newSlots(size int) *slots {
+	return &slots{
+		size:    size,
+		tenants: make(map[string]*slotTenant),
+		freed:   make(chan struct{}),
+	}
+}
+
+// register sets weight, priority and concurrency limit of tenant.
+func (s *slots) register(name string, weight, priority, max int) {
+	if weight < 1 {
+		weight = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tenantLocked(name)
+	t.weight = weight
+	t.priority = priority
+	t.max = max
+}
+
+// tenantLocked returns tenant by name, and unknown tenant is added with default weight.
+func (s *slots) tenantLocked(name string) *slotTenant {
+	t, ok := s.tenants[name]
+	if !ok {
+		t = &slotTenant{weight: 1}
+		s.tenants[name] = t
+	}
+	return t
+}
+
+func (t *slotTenant) full() bool {
+	return t.max > 0 && t.used >= t.max
+}
+
+// acquire waits until at least one slot is free for tenant, and reserves free slots up to max.
+// it returns the number of reserved slots.
+func (s *slots) acquire(ctx context.Context, name string, max int) (int, error) {
+	return s.waitGrantable(ctx, name, max, true)
+}
+
+// wait waits until at least one slot is free for tenant, and returns the number of slots up to max which tenant can reserve now.
+// Slots are not reserved.
+func (s *slots) wait(ctx context.Context, name string, max int) (int, error) {
+	return s.waitGrantable(ctx, name, max, false)
+}
+
+// tryAcquire reserves free slots up to max without waiting, and returns the number of reserved slots.
+func (s *slots) tryAcquire(name string, max int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tenantLocked(name)
+	n := s.grantableLocked(t, max)
+	t.used += n
+	s.used += n
+	return n
+}
+
+func (s *slots) waitGrantable(ctx context.Context, name string, max int, reserve bool) (int, error) {
+	s.mu.Lock()
+	t := s.tenantLocked(name)
+	t.waiting++
+	defer func() {
+		s.mu.Lock()
+		t.waiting--
+		s.notifyLocked()
+		s.mu.Unlock()
+	}()
+	for {
+		if n := s.grantableLocked(t, max); n > 0 {
+			if reserve {
+				t.used += n
+				s.used += n
+			}
+			s.mu.Unlock()
+			return n, nil
+		}
+		freed := s.freed
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-freed:
+		}
+		s.mu.Lock()
+	}
+}
+
+// grantableLocked returns the number of slots which tenant can reserve now.
+func (s *slots) grantableLocked(t *slotTenant, max int) int {
+	free := s.size - s.used
+	if free <= 0 || max <= 0 || t.full() || s.paused {
+		return 0
+	}
+	n := free
+	if t.max > 0 && t.max-t.used < n {
+		n = t.max - t.used
+	}
+	weights := t.weight
+	for _, o := range s.tenants {
+		if o == t || o.full() {
+			continue
+		}
+		if o.priority > t.priority && o.waiting > 0 {
+			return 0
+		}
+		if o.priority != t.priority {
+			continue
+		}
+		// waiting tenant which uses less slots than its weight goes first.
+		if o.waiting > 0 && o.used*t.weight < t.used*o.weight {
+			return 0
+		}
+		weights += o.weight
+	}
+	// tenant reserves slots up to its share, so that it can not hold all slots while others are idle.
+	if share := s.size*t.weight/weights - t.used; share < n {
+		n = share
+		if n < 1 {
+			n = 1
+		}
+	}
+	if max < n {
+		n = max
+	}
+	return n
+}
+
+// release frees n slots of tenant.
+// Releasing slots which are not reserved is ignored.
+func (s *slots) release(name string, n int) {
+	if n <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tenantLocked(name)
+	if n > t.used {
+		n = t.used
+	}
+	t.used -= n
+	s.used -= n
+	s.notifyLocked()
+}
+
+// setPaused stops or restarts reserving slots.
+func (s *slots) setPaused(paused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = paused
+	s.notifyLocked()
+}
+
+// resize changes the number of slots.
+// When it shrinks, reserved slots are kept until they are released, and no slots are reserved until used slots become less than size.
+func (s *slots) resize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size = size
+	s.notifyLocked()
+}
+
+// capacity returns the number of slots.
+func (s *slots) capacity() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// waitUsed waits until used slots become n or less.
+func (s *slots) waitUsed(ctx context.Context, n int) error {
+	for {
+		s.mu.Lock()
+		used, freed := s.used, s.freed
+		s.mu.Unlock()
+		if used <= n {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-freed:
+		}
+	}
+}
+
+func (s *slots) notifyLocked() {
+	close(s.freed)
+	s.freed = make(chan struct{})
+}
+
+// forTenant returns slotAcquirer which reserves slots as tenant.
+func (s *slots) forTenant(name string) tenantSlots {
+	return tenantSlots{
+		slots: s,
+		name:  name,
+	}
+}
+
+type tenantSlots struct {
+	slots *slots
+	name  string
+}
+
+func (t tenantSlots) acquire(ctx context.Context, max int) (int, error) {
+	return t.slots.acquire(ctx, t.name, max)
+}
+
+func (t tenantSlots) wait(ctx context.Context, max int) (int, error) {
+	return t.slots.wait(ctx, t.name, max)
+}
+
+func (t tenantSlots) tryAcquire(max int) int {
+	return t.slots.tryAcquire(t.name, max)
+}
+
+func (t tenantSlots) release(n int) {
+	t.slots.release(t.name, n)
+}
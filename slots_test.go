@@ -0,0 +1,139 @@
+package sqsd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlots(t *testing.T) {
+	s := newSlots(3)
+	ctx := context.Background()
+
+	n, err := s.acquire(ctx, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = s.acquire(ctx, "", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "only free slots are reserved")
+
+	acquired := make(chan int, 1)
+	go func() {
+		n, _ := s.acquire(ctx, "", 10)
+		acquired <- n
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquire must wait until any slots are freed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	s.release("", 2)
+	assert.Equal(t, 2, <-acquired)
+
+	cctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = s.acquire(cctx, "", 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// releasing slots which are not reserved is ignored.
+	s.release("", 10)
+	n, err = s.acquire(ctx, "", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
+
+func TestSlotsTenants(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("max concurrency", func(t *testing.T) {
+		s := newSlots(5)
+		s.register("a", 1, 0, 2)
+		n, err := s.acquire(ctx, "a", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		cctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err = s.acquire(cctx, "a", 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded, "tenant can not exceed its limit")
+
+		n, err = s.acquire(ctx, "b", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("weight", func(t *testing.T) {
+		s := newSlots(8)
+		s.register("a", 3, 0, 0)
+		s.register("b", 1, 0, 0)
+		n, err := s.acquire(ctx, "a", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, n, "tenant reserves slots up to its share")
+		n, err = s.acquire(ctx, "b", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		s := newSlots(2)
+		s.register("high", 1, 1, 0)
+		s.register("low", 1, 0, 0)
+		n, err := s.acquire(ctx, "low", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n, "low priority tenant uses all slots while high priority tenant is idle")
+
+		high := make(chan int, 1)
+		go func() {
+			n, _ := s.acquire(ctx, "high", 2)
+			high <- n
+		}()
+		low := make(chan int, 1)
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			n, _ := s.acquire(ctx, "low", 2)
+			low <- n
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		s.release("low", 1)
+		assert.Equal(t, 1, <-high, "high priority tenant goes first")
+		select {
+		case <-low:
+			t.Fatal("low priority tenant must wait")
+		case <-time.After(50 * time.Millisecond):
+		}
+		s.release("low", 1)
+		assert.Equal(t, 1, <-low)
+	})
+}
+
+func TestSlotsWaitAndTryAcquire(t *testing.T) {
+	s := newSlots(3)
+	ctx := context.Background()
+
+	n, err := s.wait(ctx, "", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	n, err = s.wait(ctx, "", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n, "waiting does not reserve slots")
+
+	assert.Equal(t, 2, s.tryAcquire("", 2))
+	assert.Equal(t, 1, s.tryAcquire("", 5), "only free slots are reserved")
+	assert.Equal(t, 0, s.tryAcquire("", 1), "tryAcquire does not wait")
+
+	cctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = s.wait(cctx, "", 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "wait waits until any slots are freed")
+
+	s.release("", 1)
+	n, err = s.wait(ctx, "", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,151 @@
+package sqsd
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// SQSBackend is QueueBackend for Amazon SQS.
+type SQSBackend struct {
+	client *sqs.Client
+}
+
+// NewSQSBackend returns SQSBackend object.
+func NewSQSBackend(client *sqs.Client) *SQSBackend {
+	return &SQSBackend{
+		client: client,
+	}
+}
+
+// Receive sends receive-message to SQS.
+func (b *SQSBackend) Receive(ctx context.Context, in ReceiveInput) ([]Message, error) {
+	systemAttributes := make([]types.QueueAttributeName, 0, len(in.SystemAttributes))
+	for _, name := range in.SystemAttributes {
+		systemAttributes = append(systemAttributes, types.QueueAttributeName(name))
+	}
+	out, err := b.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:              &in.QueueURL,
+		MaxNumberOfMessages:   int32(in.MaxMessages),
+		WaitTimeSeconds:       int32(in.WaitTime.Seconds()),
+		VisibilityTimeout:     int32(in.VisibilityTimeout.Seconds()),
+		AttributeNames:        systemAttributes,
+		MessageAttributeNames: in.MessageAttributes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]Message, 0, len(out.Messages))
+	for _, msg := range out.Messages {
+		msgs = append(msgs, newSQSMessage(msg))
+	}
+	return msgs, nil
+}
+
+func newSQSMessage(msg types.Message) Message {
+	attrs := make(map[string]MessageAttribute, len(msg.MessageAttributes))
+	for name, attr := range msg.MessageAttributes {
+		a := MessageAttribute{
+			BinaryValue: attr.BinaryValue,
+		}
+		if attr.DataType != nil {
+			a.DataType = *attr.DataType
+		}
+		if attr.StringValue != nil {
+			a.StringValue = *attr.StringValue
+		}
+		attrs[name] = a
+	}
+	return Message{
+		ID:               deref(msg.MessageId),
+		Payload:          deref(msg.Body),
+		Receipt:          deref(msg.ReceiptHandle),
+		SystemAttributes: msg.Attributes,
+		Attributes:       attrs,
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// DeleteMessages sends delete-message-batch to SQS.
+func (b *SQSBackend) DeleteMessages(ctx context.Context, queueURL string, receipts []string) ([]error, error) {
+	entries := make([]types.DeleteMessageBatchRequestEntry, 0, len(receipts))
+	for i := range receipts {
+		id := strconv.Itoa(i)
+		entries = append(entries, types.DeleteMessageBatchRequestEntry{
+			Id:            &id,
+			ReceiptHandle: &receipts[i],
+		})
+	}
+	out, err := b.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+		QueueUrl: &queueURL,
+		Entries:  entries,
+	})
+	if err != nil {
+		return nil, err
+	}
+	errs := make([]error, len(receipts))
+	for _, f := range out.Failed {
+		i, err := strconv.Atoi(deref(f.Id))
+		if err != nil || i < 0 || i >= len(errs) {
+			continue
+		}
+		errs[i] = &BatchEntryError{
+			Code:        deref(f.Code),
+			Message:     deref(f.Message),
+			SenderFault: f.SenderFault,
+		}
+	}
+	return errs, nil
+}
+
+// ChangeVisibility sends change-message-visibility to SQS.
+func (b *SQSBackend) ChangeVisibility(ctx context.Context, queueURL, receipt string, timeout time.Duration) error {
+	_, err := b.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          &queueURL,
+		ReceiptHandle:     &receipt,
+		VisibilityTimeout: int32(timeout.Seconds()),
+	})
+	return err
+}
+
+// Send sends send-message to SQS.
+func (b *SQSBackend) Send(ctx context.Context, in SendInput) (string, error) {
+	attrs := make(map[string]types.MessageAttributeValue, len(in.Attributes))
+	for name, attr := range in.Attributes {
+		attr := attr
+		v := types.MessageAttributeValue{
+			DataType:    &attr.DataType,
+			BinaryValue: attr.BinaryValue,
+		}
+		if !attr.IsBinary() {
+			v.StringValue = &attr.StringValue
+		}
+		attrs[name] = v
+	}
+	input := &sqs.SendMessageInput{
+		QueueUrl:          &in.QueueURL,
+		MessageBody:       &in.Body,
+		MessageAttributes: attrs,
+		DelaySeconds:      int32(in.Delay.Seconds()),
+	}
+	if in.MessageGroupID != "" {
+		input.MessageGroupId = &in.MessageGroupID
+	}
+	if in.DeduplicationID != "" {
+		input.MessageDeduplicationId = &in.DeduplicationID
+	}
+	out, err := b.client.SendMessage(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return deref(out.MessageId), nil
+}
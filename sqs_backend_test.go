@@ -0,0 +1,162 @@
+package sqsd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/assert"
+)
+
+// sqsTestServer responds to SQS JSON protocol requests by handler of each action.
+type sqsTestServer map[string]func(in map[string]any) any
+
+func (s sqsTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const prefix = "AmazonSQS."
+	target := r.Header.Get("X-Amz-Target")
+	h, ok := s[target[len(prefix):]]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var in map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&in)
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	_ = json.NewEncoder(w).Encode(h(in))
+}
+
+func newSQSTestBackend(t *testing.T, s sqsTestServer) *SQSBackend {
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return NewSQSBackend(sqs.NewFromConfig(awsConf, func(o *sqs.Options) {
+		o.BaseEndpoint = &srv.URL
+		o.DisableMessageChecksumValidation = true
+	}))
+}
+
+func TestSQSBackendReceive(t *testing.T) {
+	var received map[string]any
+	b := newSQSTestBackend(t, sqsTestServer{
+		"ReceiveMessage": func(in map[string]any) any {
+			received = in
+			return map[string]any{
+				"Messages": []map[string]any{
+					{
+						"MessageId":     "msg-1",
+						"Body":          `{"foo":"bar"}`,
+						"ReceiptHandle": "receipt-1",
+						"Attributes": map[string]string{
+							AttributeApproximateReceiveCount: "1",
+						},
+						"MessageAttributes": map[string]any{
+							"Kind": map[string]string{"DataType": "String", "StringValue": "foo"},
+						},
+					},
+				},
+			}
+		},
+	})
+
+	msgs, err := b.Receive(context.Background(), ReceiveInput{
+		QueueURL:          "http://localhost:9324/queue/test",
+		MaxMessages:       3,
+		WaitTime:          5 * time.Second,
+		VisibilityTimeout: time.Minute,
+		SystemAttributes:  []string{AttributeApproximateReceiveCount},
+		MessageAttributes: []string{"Kind"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"QueueUrl":              "http://localhost:9324/queue/test",
+		"MaxNumberOfMessages":   float64(3),
+		"WaitTimeSeconds":       float64(5),
+		"VisibilityTimeout":     float64(60),
+		"AttributeNames":        []any{"ApproximateReceiveCount"},
+		"MessageAttributeNames": []any{"Kind"},
+	}, received)
+	assert.Equal(t, []Message{
+		{
+			ID:      "msg-1",
+			Payload: `{"foo":"bar"}`,
+			Receipt: "receipt-1",
+			SystemAttributes: map[string]string{
+				AttributeApproximateReceiveCount: "1",
+			},
+			Attributes: map[string]MessageAttribute{
+				"Kind": {DataType: "String", StringValue: "foo"},
+			},
+		},
+	}, msgs)
+}
+
+func TestSQSBackendDeleteMessages(t *testing.T) {
+	b := newSQSTestBackend(t, sqsTestServer{
+		"DeleteMessageBatch": func(in map[string]any) any {
+			return map[string]any{
+				"Successful": []map[string]string{{"Id": "0"}},
+				"Failed": []map[string]any{
+					{"Id": "1", "Code": "ReceiptHandleIsInvalid", "Message": "invalid", "SenderFault": true},
+				},
+			}
+		},
+	})
+	errs, err := b.DeleteMessages(context.Background(), "http://localhost:9324/queue/test", []string{"receipt-1", "receipt-2"})
+	assert.NoError(t, err)
+	if assert.Len(t, errs, 2) {
+		assert.NoError(t, errs[0])
+		assert.Equal(t, &BatchEntryError{Code: "ReceiptHandleIsInvalid", Message: "invalid", SenderFault: true}, errs[1])
+	}
+}
+
+func TestSQSBackendSend(t *testing.T) {
+	var received map[string]any
+	b := newSQSTestBackend(t, sqsTestServer{
+		"SendMessage": func(in map[string]any) any {
+			received = in
+			return map[string]string{"MessageId": "new-id"}
+		},
+	})
+	id, err := b.Send(context.Background(), SendInput{
+		QueueURL: "http://localhost:9324/queue/test.fifo",
+		Body:     "hello",
+		Attributes: map[string]MessageAttribute{
+			"Kind": {DataType: "String", StringValue: "user"},
+			"Data": {DataType: "Binary", BinaryValue: []byte("bin")},
+		},
+		MessageGroupID:  "group-1",
+		DeduplicationID: "dedup-1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "new-id", id)
+	assert.Equal(t, map[string]any{
+		"QueueUrl":               "http://localhost:9324/queue/test.fifo",
+		"MessageBody":            "hello",
+		"MessageGroupId":         "group-1",
+		"MessageDeduplicationId": "dedup-1",
+		"MessageAttributes": map[string]any{
+			"Kind": map[string]any{"DataType": "String", "StringValue": "user"},
+			"Data": map[string]any{"DataType": "Binary", "BinaryValue": "Ymlu"},
+		},
+	}, received)
+}
+
+func TestSQSBackendChangeVisibility(t *testing.T) {
+	var received map[string]any
+	b := newSQSTestBackend(t, sqsTestServer{
+		"ChangeMessageVisibility": func(in map[string]any) any {
+			received = in
+			return map[string]any{}
+		},
+	})
+	err := b.ChangeVisibility(context.Background(), "http://localhost:9324/queue/test", "receipt-1", 90*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"QueueUrl":          "http://localhost:9324/queue/test",
+		"ReceiptHandle":     "receipt-1",
+		"VisibilityTimeout": float64(90),
+	}, received)
+}
@@ -0,0 +1,53 @@
+package sqsd_test
+
+import (
+	"context"
+	"log"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+
+	memorybackend "github.com/taiyoh/sqsd/v2/backend/memory"
+	"github.com/taiyoh/sqsd/v2/devqueue"
+)
+
+// sqsEndpointURL is SQS_ENDPOINT_URL, or URL of devqueue server in process if it is not set.
+var sqsEndpointURL string
+
+func init() {
+	var ok bool
+	if sqsEndpointURL, ok = os.LookupEnv("SQS_ENDPOINT_URL"); !ok {
+		srv := httptest.NewServer(devqueue.New(memorybackend.New()))
+		sqsEndpointURL = srv.URL
+	}
+}
+
+func newSQSClient() *sqs.Client {
+	return sqs.New(sqs.Options{
+		Region:       "ap-northeast-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("dummy", "dummy", ""),
+		BaseEndpoint: &sqsEndpointURL,
+	})
+}
+
+func setupSQS(t *testing.T, queue *sqs.Client, resourceName string) (string, error) {
+	ctx := context.Background()
+	out, err := queue.CreateQueue(ctx, &sqs.CreateQueueInput{
+		QueueName: &resourceName,
+	})
+	if err != nil {
+		return "", err
+	}
+	t.Cleanup(func() {
+		_, err := queue.DeleteQueue(ctx, &sqs.DeleteQueueInput{
+			QueueUrl: out.QueueUrl,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	})
+	return *out.QueueUrl, nil
+}
@@ -23,6 +23,12 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MonitoringServiceClient interface {
 	CurrentWorkings(ctx context.Context, in *CurrentWorkingsRequest, opts ...grpc.CallOption) (*CurrentWorkingsResponse, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error)
+	Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeResponse, error)
+	Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainResponse, error)
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	SetConcurrency(ctx context.Context, in *SetConcurrencyRequest, opts ...grpc.CallOption) (*SetConcurrencyResponse, error)
 }
 
 type monitoringServiceClient struct {
@@ -42,11 +48,71 @@ func (c *monitoringServiceClient) CurrentWorkings(ctx context.Context, in *Curre
 	return out, nil
 }
 
+func (c *monitoringServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, "/sqsd.MonitoringService/Stats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringServiceClient) Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error) {
+	out := new(PauseResponse)
+	err := c.cc.Invoke(ctx, "/sqsd.MonitoringService/Pause", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringServiceClient) Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeResponse, error) {
+	out := new(ResumeResponse)
+	err := c.cc.Invoke(ctx, "/sqsd.MonitoringService/Resume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringServiceClient) Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainResponse, error) {
+	out := new(DrainResponse)
+	err := c.cc.Invoke(ctx, "/sqsd.MonitoringService/Drain", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringServiceClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/sqsd.MonitoringService/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitoringServiceClient) SetConcurrency(ctx context.Context, in *SetConcurrencyRequest, opts ...grpc.CallOption) (*SetConcurrencyResponse, error) {
+	out := new(SetConcurrencyResponse)
+	err := c.cc.Invoke(ctx, "/sqsd.MonitoringService/SetConcurrency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MonitoringServiceServer is the server API for MonitoringService service.
 // All implementations must embed UnimplementedMonitoringServiceServer
 // for forward compatibility
 type MonitoringServiceServer interface {
 	CurrentWorkings(context.Context, *CurrentWorkingsRequest) (*CurrentWorkingsResponse, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	Pause(context.Context, *PauseRequest) (*PauseResponse, error)
+	Resume(context.Context, *ResumeRequest) (*ResumeResponse, error)
+	Drain(context.Context, *DrainRequest) (*DrainResponse, error)
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	SetConcurrency(context.Context, *SetConcurrencyRequest) (*SetConcurrencyResponse, error)
 	mustEmbedUnimplementedMonitoringServiceServer()
 }
 
@@ -57,6 +123,24 @@ type UnimplementedMonitoringServiceServer struct {
 func (UnimplementedMonitoringServiceServer) CurrentWorkings(context.Context, *CurrentWorkingsRequest) (*CurrentWorkingsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CurrentWorkings not implemented")
 }
+func (UnimplementedMonitoringServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedMonitoringServiceServer) Pause(context.Context, *PauseRequest) (*PauseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedMonitoringServiceServer) Resume(context.Context, *ResumeRequest) (*ResumeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedMonitoringServiceServer) Drain(context.Context, *DrainRequest) (*DrainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Drain not implemented")
+}
+func (UnimplementedMonitoringServiceServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedMonitoringServiceServer) SetConcurrency(context.Context, *SetConcurrencyRequest) (*SetConcurrencyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConcurrency not implemented")
+}
 func (UnimplementedMonitoringServiceServer) mustEmbedUnimplementedMonitoringServiceServer() {}
 
 // UnsafeMonitoringServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +172,114 @@ func _MonitoringService_CurrentWorkings_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MonitoringService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sqsd.MonitoringService/Stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MonitoringService_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServiceServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sqsd.MonitoringService/Pause",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServiceServer).Pause(ctx, req.(*PauseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MonitoringService_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServiceServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sqsd.MonitoringService/Resume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServiceServer).Resume(ctx, req.(*ResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MonitoringService_Drain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServiceServer).Drain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sqsd.MonitoringService/Drain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServiceServer).Drain(ctx, req.(*DrainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MonitoringService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sqsd.MonitoringService/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServiceServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MonitoringService_SetConcurrency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetConcurrencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitoringServiceServer).SetConcurrency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sqsd.MonitoringService/SetConcurrency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitoringServiceServer).SetConcurrency(ctx, req.(*SetConcurrencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MonitoringService_ServiceDesc is the grpc.ServiceDesc for MonitoringService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,6 +291,116 @@ var MonitoringService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CurrentWorkings",
 			Handler:    _MonitoringService_CurrentWorkings_Handler,
 		},
+		{
+			MethodName: "Stats",
+			Handler:    _MonitoringService_Stats_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _MonitoringService_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _MonitoringService_Resume_Handler,
+		},
+		{
+			MethodName: "Drain",
+			Handler:    _MonitoringService_Drain_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _MonitoringService_Status_Handler,
+		},
+		{
+			MethodName: "SetConcurrency",
+			Handler:    _MonitoringService_SetConcurrency_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "sqsd.proto",
+}
+
+// JobHandlerClient is the client API for JobHandler service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type JobHandlerClient interface {
+	Handle(ctx context.Context, in *Job, opts ...grpc.CallOption) (*JobResult, error)
+}
+
+type jobHandlerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJobHandlerClient(cc grpc.ClientConnInterface) JobHandlerClient {
+	return &jobHandlerClient{cc}
+}
+
+func (c *jobHandlerClient) Handle(ctx context.Context, in *Job, opts ...grpc.CallOption) (*JobResult, error) {
+	out := new(JobResult)
+	err := c.cc.Invoke(ctx, "/sqsd.JobHandler/Handle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// JobHandlerServer is the server API for JobHandler service.
+// All implementations must embed UnimplementedJobHandlerServer
+// for forward compatibility
+type JobHandlerServer interface {
+	Handle(context.Context, *Job) (*JobResult, error)
+	mustEmbedUnimplementedJobHandlerServer()
+}
+
+// UnimplementedJobHandlerServer must be embedded to have forward compatible implementations.
+type UnimplementedJobHandlerServer struct {
+}
+
+func (UnimplementedJobHandlerServer) Handle(context.Context, *Job) (*JobResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Handle not implemented")
+}
+func (UnimplementedJobHandlerServer) mustEmbedUnimplementedJobHandlerServer() {}
+
+// UnsafeJobHandlerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to JobHandlerServer will
+// result in compilation errors.
+type UnsafeJobHandlerServer interface {
+	mustEmbedUnimplementedJobHandlerServer()
+}
+
+func RegisterJobHandlerServer(s grpc.ServiceRegistrar, srv JobHandlerServer) {
+	s.RegisterService(&JobHandler_ServiceDesc, srv)
+}
+
+func _JobHandler_Handle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Job)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobHandlerServer).Handle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sqsd.JobHandler/Handle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobHandlerServer).Handle(ctx, req.(*Job))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// JobHandler_ServiceDesc is the grpc.ServiceDesc for JobHandler service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var JobHandler_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sqsd.JobHandler",
+	HandlerType: (*JobHandlerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Handle",
+			Handler:    _JobHandler_Handle_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "sqsd.proto",
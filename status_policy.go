@@ -0,0 +1,191 @@
+package sqsd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatusAction represents how worker handles message by response status of invoker.
+type StatusAction int
+
+// Actions for response status.
+const (
+	// StatusActionDelete removes message from queue.
+	StatusActionDelete StatusAction = iota
+	// StatusActionRetain keeps message in queue without changing VisibilityTimeout.
+	StatusActionRetain
+	// StatusActionRetry keeps message in queue and changes VisibilityTimeout for retry.
+	StatusActionRetry
+	// StatusActionDeadLetter gives up processing message.
+	StatusActionDeadLetter
+)
+
+var statusActionNames = map[string]StatusAction{
+	"delete":      StatusActionDelete,
+	"retain":      StatusActionRetain,
+	"retry":       StatusActionRetry,
+	"dead-letter": StatusActionDeadLetter,
+}
+
+// StatusPolicy maps range of response status code to action.
+// Delay is used as VisibilityTimeout of StatusActionRetry.
+// if Delay is zero, RetryPolicy of consumer is used.
+// In both cases, Retry-After response header takes priority.
+type StatusPolicy struct {
+	Min    int
+	Max    int
+	Action StatusAction
+	Delay  time.Duration
+}
+
+func (p StatusPolicy) match(code int) bool {
+	return p.Min <= code && code <= p.Max
+}
+
+// wrap converts err of invoker to the error which tells worker how to handle message by action.
+// it returns nil for StatusActionDelete.
+func (p StatusPolicy) wrap(err error) error {
+	switch p.Action {
+	case StatusActionRetain:
+		return fmt.Errorf("%w: %w", ErrRetainMessage, err)
+	case StatusActionRetry:
+		if p.Delay > 0 {
+			return &RetryAfterError{After: p.Delay, Err: err}
+		}
+		return err
+	case StatusActionDeadLetter:
+		return DeadLetter(err)
+	}
+	return nil
+}
+
+// matchPolicy returns the first policy which matches code.
+// if no policy matches, it returns the policy of fallback action.
+func matchPolicy(policies []StatusPolicy, code int, fallback StatusAction) StatusPolicy {
+	for _, p := range policies {
+		if p.match(code) {
+			return p
+		}
+	}
+	return StatusPolicy{Min: code, Max: code, Action: fallback}
+}
+
+// DefaultStatusPolicies is applied after user-specified policies.
+// Status codes which are not matched to any policies are treated as StatusActionDelete.
+var DefaultStatusPolicies = []StatusPolicy{
+	{Min: http.StatusTooManyRequests, Max: http.StatusTooManyRequests, Action: StatusActionRetry},
+	{Min: http.StatusInternalServerError, Max: 599, Action: StatusActionRetry},
+}
+
+// ParseStatusPolicies parses comma separated status policies.
+// Each policy is formatted as "<status>:<action>[:<delay>]".
+// status accepts single code (e.g. "429"), class (e.g. "4xx") or range (e.g. "500-503"),
+// and action accepts "delete", "retain", "retry" or "dead-letter".
+//
+//	429:retry:30s,404:dead-letter,4xx:delete
+func ParseStatusPolicies(s string) ([]StatusPolicy, error) {
+	var policies []StatusPolicy
+	for _, expr := range strings.Split(s, ",") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		parts := strings.Split(expr, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid status policy: %q", expr)
+		}
+		var p StatusPolicy
+		var err error
+		if p.Min, p.Max, err = parseStatusRange(parts[0]); err != nil {
+			return nil, fmt.Errorf("invalid status policy %q: %w", expr, err)
+		}
+		action, ok := statusActionNames[parts[1]]
+		if !ok {
+			return nil, fmt.Errorf("invalid status policy %q: unknown action", expr)
+		}
+		p.Action = action
+		if len(parts) == 3 {
+			if p.Delay, err = time.ParseDuration(parts[2]); err != nil {
+				return nil, fmt.Errorf("invalid status policy %q: %w", expr, err)
+			}
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+func parseStatusRange(s string) (int, int, error) {
+	if len(s) == 3 && strings.HasSuffix(strings.ToLower(s), "xx") {
+		class, err := strconv.Atoi(s[:1])
+		if err != nil {
+			return 0, 0, err
+		}
+		return class * 100, class*100 + 99, nil
+	}
+	if lo, hi, ok := strings.Cut(s, "-"); ok {
+		min, err := strconv.Atoi(lo)
+		if err != nil {
+			return 0, 0, err
+		}
+		max, err := strconv.Atoi(hi)
+		if err != nil {
+			return 0, 0, err
+		}
+		if min > max {
+			return 0, 0, errors.New("invalid range")
+		}
+		return min, max, nil
+	}
+	code, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, 0, err
+	}
+	return code, code, nil
+}
+
+// StatusError shows that invoker responds failure status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failure response: %d", e.StatusCode)
+}
+
+// ErrDeadLetter shows that message processing is given up.
+var ErrDeadLetter = errors.New("this message should be dead-lettered")
+
+// DeadLetter wraps err with ErrDeadLetter.
+func DeadLetter(err error) error {
+	if err == nil {
+		return ErrDeadLetter
+	}
+	return fmt.Errorf("%w: %w", ErrDeadLetter, err)
+}
+
+// parseRetryAfter parses Retry-After header value, which is seconds or HTTP-date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if sec, err := strconv.Atoi(v); err == nil {
+		if sec < 0 {
+			return 0, false
+		}
+		return time.Duration(sec) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	d := t.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
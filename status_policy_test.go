@@ -0,0 +1,56 @@
+package sqsd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStatusPolicies(t *testing.T) {
+	policies, err := ParseStatusPolicies("429:retry:30s, 404:dead-letter,500-503:retry,4xx:delete,409:retain")
+	assert.NoError(t, err)
+	assert.Equal(t, []StatusPolicy{
+		{Min: 429, Max: 429, Action: StatusActionRetry, Delay: 30 * time.Second},
+		{Min: 404, Max: 404, Action: StatusActionDeadLetter},
+		{Min: 500, Max: 503, Action: StatusActionRetry},
+		{Min: 400, Max: 499, Action: StatusActionDelete},
+		{Min: 409, Max: 409, Action: StatusActionRetain},
+	}, policies)
+
+	policies, err = ParseStatusPolicies("")
+	assert.NoError(t, err)
+	assert.Empty(t, policies)
+
+	for _, s := range []string{
+		"429",
+		"429:sleep",
+		"abc:retry",
+		"503-500:retry",
+		"429:retry:soon",
+		"429:retry:30s:foo",
+	} {
+		_, err := ParseStatusPolicies(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"Fri, 15 Mar 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Fri, 15 Mar 2024 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	} {
+		d, ok := parseRetryAfter(tt.value, now)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		assert.Equal(t, tt.want, d, tt.value)
+	}
+}
@@ -2,6 +2,8 @@ package sqsd
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -13,27 +15,48 @@ const DisableMonitoring = -1
 
 // System controls actor system of sqsd.
 type System struct {
-	gateway  *Gateway
-	port     int
-	capacity int
-	invoker  Invoker
+	gateways  []*Gateway
+	port      int
+	capacity  int
+	invoker   Invoker
+	params    []ConsumerParameter
+	scheduler *Scheduler
+
+	mu     sync.Mutex
+	worker *worker
 }
 
 // SystemBuilder provides constructor for system object requirements.
 type SystemBuilder func(*System)
 
 // GatewayBuilder builds gateway for system.
+// System consumes multiple queues by adding GatewayBuilder for each queue,
+// and messages of all queues are processed by the shared consumer.
 func GatewayBuilder(queue *sqs.Client, queueURL string, parallel int, timeout time.Duration, params ...GatewayParameter) SystemBuilder {
+	return BackendGatewayBuilder(NewSQSBackend(queue), queueURL, params...)
+}
+
+// BackendGatewayBuilder builds gateway which receives messages from queue backend.
+func BackendGatewayBuilder(backend QueueBackend, queueURL string, params ...GatewayParameter) SystemBuilder {
 	return func(s *System) {
-		s.gateway = NewGateway(queue, queueURL, params...)
+		s.gateways = append(s.gateways, NewGateway(backend, queueURL, params...))
 	}
 }
 
 // ConsumerBuilder builds consumer for system.
-func ConsumerBuilder(invoker Invoker, parallel int) SystemBuilder {
+func ConsumerBuilder(invoker Invoker, parallel int, params ...ConsumerParameter) SystemBuilder {
 	return func(s *System) {
 		s.capacity = parallel
 		s.invoker = invoker
+		s.params = params
+	}
+}
+
+// SchedulerBuilder sets scheduler of periodic tasks to system.
+// Periodic tasks are invoked by the invoker of consumer.
+func SchedulerBuilder(scheduler *Scheduler) SystemBuilder {
+	return func(s *System) {
+		s.scheduler = scheduler
 	}
 }
 
@@ -57,8 +80,49 @@ func NewSystem(builders ...SystemBuilder) *System {
 
 // Run starts running actors and gRPC server.
 func (s *System) Run(ctx context.Context) error {
+	if len(s.gateways) == 0 {
+		return errors.New("no queue is registered")
+	}
+	router := make(gatewayRouter, len(s.gateways))
+	invokers := make(map[string]Invoker)
+	for _, g := range s.gateways {
+		if _, ok := router[g.queueURL]; ok {
+			return fmt.Errorf("queue is registered twice: %s", g.queueURL)
+		}
+		router[g.queueURL] = g
+		if g.invoker != nil {
+			invokers[g.queueURL] = g.invoker
+		}
+	}
+	ivk := s.invoker
+	if len(invokers) > 0 {
+		ivk = queueInvokers{
+			fallback: s.invoker,
+			invokers: invokers,
+		}
+	}
+
+	for _, p := range processPools(ivk) {
+		if err := p.start(s.capacity); err != nil {
+			return err
+		}
+		defer p.Close()
+	}
+
 	msgsCh := make(chan Message, s.capacity)
-	worker := startWorker(ctx, s.invoker, msgsCh, s.gateway)
+	worker := startWorker(ctx, ivk, msgsCh, router, s.params...)
+	if worker.params.maxAttempts > 0 {
+		// max attempts of dead-letter is never reached without ApproximateReceiveCount.
+		for _, g := range s.gateways {
+			g.input.SystemAttributes = withSystemAttribute(g.input.SystemAttributes, AttributeApproximateReceiveCount)
+		}
+	}
+	for _, g := range s.gateways {
+		worker.slots.register(g.queueURL, g.weight, g.priority, g.maxConcurrency)
+	}
+	s.mu.Lock()
+	s.worker = worker
+	s.mu.Unlock()
 
 	monitor := NewMonitoringService(worker)
 
@@ -73,12 +137,29 @@ func (s *System) Run(ctx context.Context) error {
 	}
 
 	var wg sync.WaitGroup
+	var fetchers sync.WaitGroup
+	for _, g := range s.gateways {
+		fetchers.Add(1)
+		go func(g *Gateway) {
+			defer fetchers.Done()
+			g.start(ctx, msgsCh, worker.slots.forTenant(g.queueURL))
+		}(g)
+	}
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		s.gateway.start(ctx, msgsCh)
+		fetchers.Wait()
+		close(msgsCh)
 	}()
 
+	if s.scheduler != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.scheduler.Run(ctx, s.invoker)
+		}()
+	}
+
 	<-ctx.Done()
 	getLogger().Info("signal caught. stopping worker...")
 
@@ -87,6 +168,21 @@ func (s *System) Run(ctx context.Context) error {
 	}
 
 	wg.Wait()
+	// messages which are not started are given back after fetchers stop.
+	<-worker.dispatcherDone
 
 	return nil
 }
+
+// SetConcurrency changes parallel count of consumer while system is running, and returns the previous one.
+// When it shrinks, running tasks are not interrupted, and it waits until they finish to fit in the new count.
+// Worker processes of ProcessPoolInvoker are not resized.
+func (s *System) SetConcurrency(ctx context.Context, n int) (int, error) {
+	s.mu.Lock()
+	w := s.worker
+	s.mu.Unlock()
+	if w == nil {
+		return 0, errors.New("system is not running")
+	}
+	return w.SetConcurrency(ctx, n)
+}
@@ -1,4 +1,4 @@
-package sqsd
+package sqsd_test
 
 import (
 	"context"
@@ -9,13 +9,14 @@ import (
 	"testing"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/stretchr/testify/assert"
+
+	sqsd "github.com/taiyoh/sqsd/v2"
 )
 
 func TestSystem(t *testing.T) {
 	resourceName := fmt.Sprintf("system-%d", time.Now().UnixNano())
-	queue := sqs.NewFromConfig(awsConf, sqsEndpoint)
+	queue := newSQSClient()
 	queueURL, err := setupSQS(t, queue, resourceName)
 	if err != nil {
 		panic(err)
@@ -27,10 +28,10 @@ func TestSystem(t *testing.T) {
 	port, err := strconv.Atoi(strings.Split(l.Addr().String(), ":")[1])
 	assert.NoError(t, err)
 	l.Close()
-	sys := NewSystem(
-		GatewayBuilder(queue, queueURL, 1, time.Hour),
-		ConsumerBuilder(nil, 3),
-		MonitorBuilder(port),
+	sys := sqsd.NewSystem(
+		sqsd.GatewayBuilder(queue, queueURL, 1, time.Hour),
+		sqsd.ConsumerBuilder(nil, 3),
+		sqsd.MonitorBuilder(port),
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
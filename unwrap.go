@@ -0,0 +1,297 @@
+package sqsd
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// UnwrapInvoker unwraps envelope of message before invoking worker.
+//
+//   - SNS notification: only Message is passed, and MessageAttributes are mapped to message attributes.
+//   - S3 event notification: each of Records is invoked separately in order.
+//   - EventBridge event: only detail is passed, and detail-type and source are mapped to message attributes.
+//
+// SNS notification which wraps S3 or EventBridge event is unwrapped twice.
+// Other messages are passed as they are.
+//
+// Records of S3 event are parts of one message, so that message is retried as a whole when any of them fails,
+// and records which have succeeded are invoked again on redelivery.
+// Worker must handle records idempotently.
+// By default, the first error stops invoking rest of records, and UnwrapInvokeAllRecords changes it.
+type UnwrapInvoker struct {
+	invoker   Invoker
+	certs     []*x509.Certificate
+	invokeAll bool
+}
+
+type unwrapParams struct {
+	certs     []*x509.Certificate
+	invokeAll bool
+}
+
+// UnwrapParameter sets parameter to UnwrapInvoker by functional option pattern.
+type UnwrapParameter func(*unwrapParams)
+
+// UnwrapSNSCertificates makes UnwrapInvoker verify signature of SNS notification by certificates.
+// Notification which is not signed by any of them is dead-lettered.
+// SigningCertURL in notification is not fetched.
+func UnwrapSNSCertificates(certs ...*x509.Certificate) UnwrapParameter {
+	return func(p *unwrapParams) {
+		p.certs = append(p.certs, certs...)
+	}
+}
+
+// UnwrapInvokeAllRecords makes UnwrapInvoker invoke all records of S3 event even if some of them fail,
+// and return errors of them joined.
+// Message is handled by the joined error, so that it is retried if any record fails.
+func UnwrapInvokeAllRecords() UnwrapParameter {
+	return func(p *unwrapParams) {
+		p.invokeAll = true
+	}
+}
+
+// NewUnwrapInvoker returns UnwrapInvoker object which invokes ivk with unwrapped messages.
+func NewUnwrapInvoker(ivk Invoker, params ...UnwrapParameter) *UnwrapInvoker {
+	var p unwrapParams
+	for _, fn := range params {
+		fn(&p)
+	}
+	return &UnwrapInvoker{
+		invoker:   ivk,
+		certs:     p.certs,
+		invokeAll: p.invokeAll,
+	}
+}
+
+// LoadSNSCertificate reads PEM encoded certificate of SNS from file.
+func LoadSNSCertificate(path string) (*x509.Certificate, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no certificate is found in %s", path)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// Unwrap returns the invoker which is invoked with unwrapped messages.
+func (u *UnwrapInvoker) Unwrap() Invoker {
+	return u.invoker
+}
+
+// Invoke invokes worker with unwrapped messages.
+// When S3 event is split, records are invoked in order, and the first error stops invoking rest of them
+// unless UnwrapInvokeAllRecords is given.
+func (u *UnwrapInvoker) Invoke(ctx context.Context, msg Message) error {
+	msgs, err := u.unwrap(msg)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, m := range msgs {
+		if err := u.invoker.Invoke(ctx, m); err != nil {
+			if !u.invokeAll {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (u *UnwrapInvoker) unwrap(msg Message) ([]Message, error) {
+	if n, ok := parseSNSNotification(msg.Payload); ok {
+		if len(u.certs) > 0 {
+			if err := n.verify(u.certs); err != nil {
+				return nil, DeadLetter(err)
+			}
+		}
+		msg = n.apply(msg)
+	}
+	if records, ok := parseS3Event(msg.Payload); ok {
+		msgs := make([]Message, 0, len(records))
+		for _, rec := range records {
+			m := msg
+			m.Payload = string(rec)
+			msgs = append(msgs, m)
+		}
+		return msgs, nil
+	}
+	if ev, ok := parseEventBridgeEvent(msg.Payload); ok {
+		msg = ev.apply(msg)
+	}
+	return []Message{msg}, nil
+}
+
+// withAttributes returns copy of attributes of msg, so that original message is not modified.
+func withAttributes(msg Message, attrs map[string]MessageAttribute) Message {
+	merged := make(map[string]MessageAttribute, len(msg.Attributes)+len(attrs))
+	for name, attr := range msg.Attributes {
+		merged[name] = attr
+	}
+	for name, attr := range attrs {
+		merged[name] = attr
+	}
+	msg.Attributes = merged
+	return msg
+}
+
+type snsNotification struct {
+	Type              string
+	MessageId         string
+	TopicArn          string
+	Subject           string
+	Message           string
+	Timestamp         string
+	SignatureVersion  string
+	Signature         string
+	MessageAttributes map[string]snsAttribute
+}
+
+type snsAttribute struct {
+	Type  string
+	Value string
+}
+
+func parseSNSNotification(payload string) (snsNotification, bool) {
+	var n snsNotification
+	if !strings.HasPrefix(strings.TrimSpace(payload), "{") {
+		return n, false
+	}
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return n, false
+	}
+	return n, n.Type == "Notification" && n.MessageId != "" && n.TopicArn != ""
+}
+
+func (n snsNotification) apply(msg Message) Message {
+	msg.Payload = n.Message
+	if len(n.MessageAttributes) == 0 {
+		return msg
+	}
+	attrs := make(map[string]MessageAttribute, len(n.MessageAttributes))
+	for name, attr := range n.MessageAttributes {
+		a := MessageAttribute{DataType: attr.Type, StringValue: attr.Value}
+		if attr.Type == "Binary" {
+			b, err := base64.StdEncoding.DecodeString(attr.Value)
+			if err != nil {
+				continue
+			}
+			a = MessageAttribute{DataType: attr.Type, BinaryValue: b}
+		}
+		attrs[name] = a
+	}
+	return withAttributes(msg, attrs)
+}
+
+// stringToSign builds the string which is signed by SNS.
+func (n snsNotification) stringToSign() string {
+	var b strings.Builder
+	add := func(key, val string) {
+		b.WriteString(key + "\n" + val + "\n")
+	}
+	add("Message", n.Message)
+	add("MessageId", n.MessageId)
+	if n.Subject != "" {
+		add("Subject", n.Subject)
+	}
+	add("Timestamp", n.Timestamp)
+	add("TopicArn", n.TopicArn)
+	add("Type", n.Type)
+	return b.String()
+}
+
+func (n snsNotification) verify(certs []*x509.Certificate) error {
+	sig, err := base64.StdEncoding.DecodeString(n.Signature)
+	if err != nil || len(sig) == 0 {
+		return errors.New("SNS notification is not signed")
+	}
+	var hash crypto.Hash
+	var digest []byte
+	switch n.SignatureVersion {
+	case "1":
+		sum := sha1.Sum([]byte(n.stringToSign()))
+		hash, digest = crypto.SHA1, sum[:]
+	case "2":
+		sum := sha256.Sum256([]byte(n.stringToSign()))
+		hash, digest = crypto.SHA256, sum[:]
+	default:
+		return fmt.Errorf("unsupported SignatureVersion of SNS notification: %q", n.SignatureVersion)
+	}
+	for _, cert := range certs {
+		pub, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+		if rsa.VerifyPKCS1v15(pub, hash, digest, sig) == nil {
+			return nil
+		}
+	}
+	return errors.New("signature of SNS notification is invalid")
+}
+
+// parseS3Event returns Records of S3 event notification.
+func parseS3Event(payload string) ([]json.RawMessage, bool) {
+	var ev struct {
+		Records []json.RawMessage
+	}
+	if !strings.HasPrefix(strings.TrimSpace(payload), "{") {
+		return nil, false
+	}
+	if err := json.Unmarshal([]byte(payload), &ev); err != nil || len(ev.Records) == 0 {
+		return nil, false
+	}
+	for _, rec := range ev.Records {
+		var r struct {
+			EventSource string `json:"eventSource"`
+		}
+		if err := json.Unmarshal(rec, &r); err != nil || r.EventSource != "aws:s3" {
+			return nil, false
+		}
+	}
+	return ev.Records, true
+}
+
+// EventBridge events are mapped to these message attributes.
+const (
+	AttributeEventBridgeDetailType = "EventBridge.DetailType"
+	AttributeEventBridgeSource     = "EventBridge.Source"
+)
+
+type eventBridgeEvent struct {
+	DetailType string          `json:"detail-type"`
+	Source     string          `json:"source"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+func parseEventBridgeEvent(payload string) (eventBridgeEvent, bool) {
+	var ev eventBridgeEvent
+	if !strings.HasPrefix(strings.TrimSpace(payload), "{") {
+		return ev, false
+	}
+	if err := json.Unmarshal([]byte(payload), &ev); err != nil {
+		return ev, false
+	}
+	return ev, ev.DetailType != "" && ev.Source != "" && len(ev.Detail) > 0
+}
+
+func (ev eventBridgeEvent) apply(msg Message) Message {
+	msg.Payload = string(ev.Detail)
+	return withAttributes(msg, map[string]MessageAttribute{
+		AttributeEventBridgeDetailType: {DataType: "String", StringValue: ev.DetailType},
+		AttributeEventBridgeSource:     {DataType: "String", StringValue: ev.Source},
+	})
+}
@@ -0,0 +1,199 @@
+package sqsd
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unwrapRecorder struct {
+	msgs []Message
+	err  error
+}
+
+func (r *unwrapRecorder) invoker() Invoker {
+	return testInvoker(func(ctx context.Context, msg Message) error {
+		r.msgs = append(r.msgs, msg)
+		return r.err
+	})
+}
+
+func newSNSTestCertificate(t *testing.T) (*rsa.PrivateKey, *x509.Certificate, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.ap-northeast-1.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return key, cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func signSNS(t *testing.T, key *rsa.PrivateKey, n snsNotification) string {
+	n.SignatureVersion = "2"
+	sum := sha256.Sum256([]byte(n.stringToSign()))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	require.NoError(t, err)
+	n.Signature = base64.StdEncoding.EncodeToString(sig)
+	b, err := json.Marshal(n)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestUnwrapInvokerSNS(t *testing.T) {
+	ctx := context.Background()
+	var rec unwrapRecorder
+	ivk := NewUnwrapInvoker(rec.invoker())
+
+	msg := Message{
+		ID: "msg-1",
+		Payload: `{"Type":"Notification","MessageId":"sns-1","TopicArn":"arn:aws:sns:ap-northeast-1:123456789012:topic",` +
+			`"Message":"{\"foo\":\"bar\"}","Timestamp":"2024-01-01T00:00:00.000Z",` +
+			`"MessageAttributes":{"Kind":{"Type":"String","Value":"user"},"Raw":{"Type":"Binary","Value":"AAEC"}}}`,
+		Attributes: map[string]MessageAttribute{
+			"Origin": {DataType: "String", StringValue: "sqs"},
+		},
+	}
+	require.NoError(t, ivk.Invoke(ctx, msg))
+	require.Len(t, rec.msgs, 1)
+	assert.Equal(t, "msg-1", rec.msgs[0].ID)
+	assert.Equal(t, `{"foo":"bar"}`, rec.msgs[0].Payload)
+	assert.Equal(t, map[string]MessageAttribute{
+		"Origin": {DataType: "String", StringValue: "sqs"},
+		"Kind":   {DataType: "String", StringValue: "user"},
+		"Raw":    {DataType: "Binary", BinaryValue: []byte{0, 1, 2}},
+	}, rec.msgs[0].Attributes)
+	assert.Len(t, msg.Attributes, 1, "original message should not be modified")
+
+	// plain message is passed as it is.
+	rec.msgs = nil
+	plain := Message{ID: "msg-2", Payload: `{"Type":"Notification"}`}
+	require.NoError(t, ivk.Invoke(ctx, plain))
+	assert.Equal(t, []Message{plain}, rec.msgs)
+}
+
+func TestUnwrapInvokerSNSSignature(t *testing.T) {
+	ctx := context.Background()
+	key, cert, certPEM := newSNSTestCertificate(t)
+	path := filepath.Join(t.TempDir(), "sns.pem")
+	require.NoError(t, os.WriteFile(path, certPEM, 0o644))
+	loaded, err := LoadSNSCertificate(path)
+	require.NoError(t, err)
+	assert.Equal(t, cert.Raw, loaded.Raw)
+
+	var rec unwrapRecorder
+	ivk := NewUnwrapInvoker(rec.invoker(), UnwrapSNSCertificates(loaded))
+
+	n := snsNotification{
+		Type:      "Notification",
+		MessageId: "sns-1",
+		TopicArn:  "arn:aws:sns:ap-northeast-1:123456789012:topic",
+		Subject:   "hello",
+		Message:   "signed",
+		Timestamp: "2024-01-01T00:00:00.000Z",
+	}
+	require.NoError(t, ivk.Invoke(ctx, Message{Payload: signSNS(t, key, n)}))
+	if assert.Len(t, rec.msgs, 1) {
+		assert.Equal(t, "signed", rec.msgs[0].Payload)
+	}
+
+	var tampered snsNotification
+	require.NoError(t, json.Unmarshal([]byte(signSNS(t, key, n)), &tampered))
+	tampered.Message = "tampered"
+	b, err := json.Marshal(tampered)
+	require.NoError(t, err)
+	err = ivk.Invoke(ctx, Message{Payload: string(b)})
+	assert.ErrorIs(t, err, ErrDeadLetter)
+
+	n.Message = "unsigned"
+	b, err = json.Marshal(n)
+	require.NoError(t, err)
+	err = ivk.Invoke(ctx, Message{Payload: string(b)})
+	assert.ErrorIs(t, err, ErrDeadLetter)
+	assert.Len(t, rec.msgs, 1)
+}
+
+func TestUnwrapInvokerS3Event(t *testing.T) {
+	ctx := context.Background()
+	var rec unwrapRecorder
+	ivk := NewUnwrapInvoker(rec.invoker())
+
+	event := `{"Records":[{"eventSource":"aws:s3","s3":{"object":{"key":"a.txt"}}},{"eventSource":"aws:s3","s3":{"object":{"key":"b.txt"}}}]}`
+	payload, err := json.Marshal(snsNotification{
+		Type:      "Notification",
+		MessageId: "sns-1",
+		TopicArn:  "arn:aws:sns:ap-northeast-1:123456789012:topic",
+		Message:   event,
+	})
+	require.NoError(t, err)
+
+	// S3 event is unwrapped even if it is delivered via SNS.
+	for _, p := range []string{event, string(payload)} {
+		rec.msgs = nil
+		require.NoError(t, ivk.Invoke(ctx, Message{ID: "msg-1", Payload: p}))
+		if assert.Len(t, rec.msgs, 2) {
+			assert.JSONEq(t, `{"eventSource":"aws:s3","s3":{"object":{"key":"a.txt"}}}`, rec.msgs[0].Payload)
+			assert.JSONEq(t, `{"eventSource":"aws:s3","s3":{"object":{"key":"b.txt"}}}`, rec.msgs[1].Payload)
+			assert.Equal(t, "msg-1", rec.msgs[1].ID)
+		}
+	}
+
+	// the first error stops invoking rest of records.
+	rec.msgs = nil
+	rec.err = errors.New("failed")
+	assert.ErrorIs(t, ivk.Invoke(ctx, Message{Payload: event}), rec.err)
+	assert.Len(t, rec.msgs, 1)
+
+	// all records are invoked, and errors are joined.
+	rec.msgs = nil
+	all := NewUnwrapInvoker(rec.invoker(), UnwrapInvokeAllRecords())
+	err = all.Invoke(ctx, Message{Payload: event})
+	assert.ErrorIs(t, err, rec.err)
+	assert.EqualError(t, err, "failed\nfailed")
+	assert.Len(t, rec.msgs, 2)
+
+	// records from other sources are passed as they are.
+	rec.msgs = nil
+	rec.err = nil
+	other := `{"Records":[{"eventSource":"aws:dynamodb"}]}`
+	require.NoError(t, ivk.Invoke(ctx, Message{Payload: other}))
+	if assert.Len(t, rec.msgs, 1) {
+		assert.Equal(t, other, rec.msgs[0].Payload)
+	}
+}
+
+func TestUnwrapInvokerEventBridge(t *testing.T) {
+	ctx := context.Background()
+	var rec unwrapRecorder
+	ivk := NewUnwrapInvoker(rec.invoker())
+
+	event := `{"version":"0","id":"ev-1","detail-type":"Order Created","source":"com.example.orders","detail":{"order_id":1}}`
+	require.NoError(t, ivk.Invoke(ctx, Message{Payload: event}))
+	require.Len(t, rec.msgs, 1)
+	assert.JSONEq(t, `{"order_id":1}`, rec.msgs[0].Payload)
+	assert.Equal(t, map[string]MessageAttribute{
+		AttributeEventBridgeDetailType: {DataType: "String", StringValue: "Order Created"},
+		AttributeEventBridgeSource:     {DataType: "String", StringValue: "com.example.orders"},
+	}, rec.msgs[0].Attributes)
+}